@@ -0,0 +1,336 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	adminLoggersPath = "/loggers"
+	adminStatusPath  = "/status"
+)
+
+// AdminHandlerOption represents available options for admin handler.
+type AdminHandlerOption struct {
+	// Context is the logger context to manage, the context of bound provider will be
+	// used if it's nil.
+	Context *LoggerContext
+	// Prefix will be trimmed from the request path, it's useful if the handler is not
+	// mounted with http.StripPrefix.
+	Prefix string
+}
+
+type adminHandler struct {
+	opts    *AdminHandlerOption
+	locker  sync.Mutex
+	reverts map[string]*levelRevert
+}
+
+// levelRevert holds the level of logger before a temporary level change.
+type levelRevert struct {
+	timer    *time.Timer
+	revertAt time.Time
+	level    Level
+	hasLevel bool
+}
+
+type loggerInfo struct {
+	Name            string     `json:"name"`
+	Level           string     `json:"level"`
+	ConfiguredLevel string     `json:"configured_level,omitempty"`
+	RevertAt        *time.Time `json:"revert_at,omitempty"`
+	Additive        bool       `json:"additive"`
+	Writers         []string   `json:"writers"`
+}
+
+type writerStatus struct {
+	Name    string `json:"name"`
+	Logger  string `json:"logger"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type adminStatus struct {
+	Healthy bool           `json:"healthy"`
+	Writers []writerStatus `json:"writers"`
+}
+
+type levelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+// NewAdminHandler creates a http handler to inspect loggers and change levels at runtime.
+// The following endpoints are supported:
+//
+//	GET /loggers             lists all the loggers with level and writers
+//	GET /loggers/{name}      gets the logger with given name
+//	PUT|POST /loggers/{name} sets level with body {"level": "DEBUG", "ttl": "10m"},
+//	                         the level reverts after ttl if set, and the logger will
+//	                         inherit level from ancestors if level is empty, only
+//	                         the existing loggers can be changed
+//	GET /status              reports the health of all writers
+func NewAdminHandler(options ...func(*AdminHandlerOption)) http.Handler {
+	opts := &AdminHandlerOption{}
+
+	for _, f := range options {
+		f(opts)
+	}
+
+	return &adminHandler{
+		opts:    opts,
+		reverts: make(map[string]*levelRevert),
+	}
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := h.context()
+	if !ok {
+		writeAdminError(w, http.StatusServiceUnavailable,
+			errors.New("logger factory of bound provider is not LoggerContext"))
+		return
+	}
+
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, h.opts.Prefix), "/")
+	switch {
+	case path == adminLoggersPath:
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		h.listLoggers(w, ctx)
+	case strings.HasPrefix(path, adminLoggersPath+"/"):
+		name := strings.TrimPrefix(path, adminLoggersPath+"/")
+		if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			h.getLogger(w, ctx, name)
+		} else {
+			h.setLevel(w, r, ctx, name)
+		}
+	case path == adminStatusPath:
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		h.status(w, ctx)
+	default:
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("no such endpoint: %s", path))
+	}
+}
+
+func (h *adminHandler) context() (*LoggerContext, bool) {
+	if h.opts.Context != nil {
+		return h.opts.Context, true
+	}
+	ctx, ok := getLoggerFactory().(*LoggerContext)
+
+	return ctx, ok
+}
+
+func (h *adminHandler) listLoggers(w http.ResponseWriter, ctx *LoggerContext) {
+	loggers := ctx.loggers()
+	infos := make([]loggerInfo, 0, len(loggers))
+	for _, logger := range loggers {
+		infos = append(infos, h.loggerInfo(logger))
+	}
+
+	writeAdminJson(w, http.StatusOK, infos)
+}
+
+func (h *adminHandler) getLogger(w http.ResponseWriter, ctx *LoggerContext, name string) {
+	logger, ok := ctx.findLogger(name)
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("no such logger: %s", name))
+		return
+	}
+
+	writeAdminJson(w, http.StatusOK, h.loggerInfo(logger))
+}
+
+func (h *adminHandler) setLevel(w http.ResponseWriter, r *http.Request,
+	ctx *LoggerContext, name string) {
+	var req levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+
+	var ttl time.Duration
+	if len(req.TTL) != 0 {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid ttl: %s", req.TTL))
+			return
+		}
+	}
+
+	inherit := len(strings.TrimSpace(req.Level)) == 0
+	lvl, ok := LookupLevel(req.Level)
+	if !inherit && !ok {
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid level: %s", req.Level))
+		return
+	}
+
+	// never create loggers from request
+	logger, ok := ctx.findLogger(name)
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("no such logger: %s", name))
+		return
+	}
+	if inherit && logger.isRootLogger() {
+		writeAdminError(w, http.StatusBadRequest, errors.New("root logger must have a level"))
+		return
+	}
+
+	h.locker.Lock()
+	h.scheduleRevert(logger, ttl)
+	if inherit {
+		logger.ResetLevel()
+	} else {
+		logger.SetLevel(lvl)
+	}
+	h.locker.Unlock()
+
+	writeAdminJson(w, http.StatusOK, h.loggerInfo(logger))
+}
+
+// scheduleRevert cancels the pending revert of logger, and schedules a new one to
+// revert to the level before the first temporary change if ttl is set. This must be
+// called with locker held.
+func (h *adminHandler) scheduleRevert(logger *namedLogger, ttl time.Duration) {
+	rv, pending := h.reverts[logger.name]
+	if pending {
+		rv.timer.Stop()
+		delete(h.reverts, logger.name)
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	if !pending {
+		rv = &levelRevert{}
+		rv.level, rv.hasLevel = logger.ExplicitLevel()
+	}
+	rv.revertAt = time.Now().Add(ttl)
+	rv.timer = time.AfterFunc(ttl, func() {
+		h.revert(logger, rv)
+	})
+	h.reverts[logger.name] = rv
+}
+
+func (h *adminHandler) revert(logger *namedLogger, rv *levelRevert) {
+	h.locker.Lock()
+	defer h.locker.Unlock()
+
+	// the revert has been canceled or replaced
+	if h.reverts[logger.name] != rv {
+		return
+	}
+	delete(h.reverts, logger.name)
+
+	if rv.hasLevel {
+		logger.SetLevel(rv.level)
+	} else {
+		logger.ResetLevel()
+	}
+}
+
+func (h *adminHandler) status(w http.ResponseWriter, ctx *LoggerContext) {
+	status := adminStatus{
+		Healthy: true,
+		Writers: make([]writerStatus, 0),
+	}
+	for _, logger := range ctx.loggers() {
+		writers, _ := logger.attachedWriters()
+		for _, writer := range writers {
+			ws := writerStatus{
+				Name:    writer.Name(),
+				Logger:  logger.name,
+				Healthy: true,
+			}
+			if err := checkHealth(writer); err != nil {
+				ws.Healthy = false
+				ws.Error = err.Error()
+				status.Healthy = false
+			}
+			status.Writers = append(status.Writers, ws)
+		}
+	}
+
+	code := http.StatusOK
+	if !status.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	writeAdminJson(w, code, status)
+}
+
+func (h *adminHandler) loggerInfo(logger *namedLogger) loggerInfo {
+	writers, additive := logger.attachedWriters()
+	info := loggerInfo{
+		Name:     logger.name,
+		Level:    logger.EffectiveLevel().String(),
+		Additive: additive,
+		Writers:  make([]string, 0, len(writers)),
+	}
+	if lvl, ok := logger.ExplicitLevel(); ok {
+		info.ConfiguredLevel = lvl.String()
+	}
+	for _, writer := range writers {
+		info.Writers = append(info.Writers, writer.Name())
+	}
+
+	h.locker.Lock()
+	if rv, ok := h.reverts[logger.name]; ok {
+		revertAt := rv.revertAt
+		info.RevertAt = &revertAt
+	}
+	h.locker.Unlock()
+
+	return info
+}
+
+// allowMethods checks if the request method is allowed, and responds with 405 if not.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeAdminError(w, http.StatusMethodNotAllowed,
+		fmt.Errorf("method not allowed: %s", r.Method))
+
+	return false
+}
+
+func writeAdminJson(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		Reportf("admin handler write response error: %v", err)
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, code int, err error) {
+	writeAdminJson(w, code, map[string]string{"error": err.Error()})
+}
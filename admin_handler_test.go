@@ -0,0 +1,143 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type unhealthyWriter struct {
+	eventCollector
+}
+
+func (w *unhealthyWriter) Name() string {
+	return "UNHEALTHY"
+}
+
+func (w *unhealthyWriter) Health() error {
+	return errors.New("disk full")
+}
+
+var _ = ginkgo.Describe("admin handler", func() {
+	var ctx *LoggerContext
+	var handler http.Handler
+
+	ginkgo.BeforeEach(func() {
+		ctx, _ = newCollectorContext()
+		ctx.RealLogger("github.com/acme/db").SetLevel(DebugLevel)
+		handler = NewAdminHandler(func(o *AdminHandlerOption) {
+			o.Context = ctx
+			o.Prefix = "/admin"
+		})
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	decode := func(rec *httptest.ResponseRecorder, v interface{}) {
+		Expect(json.Unmarshal(rec.Body.Bytes(), v)).To(BeNil())
+	}
+
+	ginkgo.It("list loggers", func() {
+		rec := serve(http.MethodGet, "/admin/loggers", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var infos []loggerInfo
+		decode(rec, &infos)
+		Expect(infos).To(HaveLen(4))
+		Expect(infos[0].Name).To(Equal(RootLoggerName))
+		Expect(infos[0].Writers).To(Equal([]string{"COLLECTOR"}))
+		Expect(infos[1].Name).To(Equal("github.com"))
+		Expect(infos[1].ConfiguredLevel).To(BeEmpty())
+		Expect(infos[3].Name).To(Equal("github.com/acme/db"))
+		Expect(infos[3].Level).To(Equal("DEBUG"))
+		Expect(infos[3].ConfiguredLevel).To(Equal("DEBUG"))
+
+		Expect(serve(http.MethodGet, "/admin/loggers/github.com/none", "").Code).
+			To(Equal(http.StatusNotFound))
+		Expect(serve(http.MethodDelete, "/admin/loggers", "").Code).
+			To(Equal(http.StatusMethodNotAllowed))
+	})
+	ginkgo.It("set level", func() {
+		rec := serve(http.MethodPut, "/admin/loggers/github.com/acme", `{"level":"warn"}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(ctx.RealLogger("github.com/acme").EffectiveLevel()).To(Equal(WarnLevel))
+
+		rec = serve(http.MethodPost, "/admin/loggers/github.com/acme/db", `{"level":""}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var info loggerInfo
+		decode(rec, &info)
+		Expect(info.Level).To(Equal("WARN"))
+		Expect(info.ConfiguredLevel).To(BeEmpty())
+
+		Expect(serve(http.MethodPut, "/admin/loggers/github.com/none", `{"level":"warn"}`).Code).
+			To(Equal(http.StatusNotFound))
+		_, ok := ctx.findLogger("github.com/none")
+		Expect(ok).To(BeFalse())
+		Expect(serve(http.MethodPut, "/admin/loggers/ROOT", `{"level":""}`).Code).
+			To(Equal(http.StatusBadRequest))
+		Expect(serve(http.MethodPut, "/admin/loggers/ROOT", `{"level":"verbose"}`).Code).
+			To(Equal(http.StatusBadRequest))
+		Expect(serve(http.MethodPut, "/admin/loggers/ROOT", `{"level":"info","ttl":"x"}`).Code).
+			To(Equal(http.StatusBadRequest))
+	})
+	ginkgo.It("set level with ttl", func() {
+		db := ctx.RealLogger("github.com/acme/db")
+		rec := serve(http.MethodPut, "/admin/loggers/github.com/acme/db",
+			`{"level":"trace","ttl":"20ms"}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var info loggerInfo
+		decode(rec, &info)
+		Expect(info.RevertAt).NotTo(BeNil())
+		Expect(db.EffectiveLevel()).To(Equal(TraceLevel))
+
+		// the second change still reverts to the level before the first change
+		serve(http.MethodPut, "/admin/loggers/github.com/acme/db", `{"level":"error","ttl":"20ms"}`)
+		Expect(db.EffectiveLevel()).To(Equal(ErrorLevel))
+		Eventually(db.EffectiveLevel, time.Second, 5*time.Millisecond).Should(Equal(DebugLevel))
+
+		serve(http.MethodPut, "/admin/loggers/github.com/acme/db", `{"level":"info","ttl":"20ms"}`)
+		serve(http.MethodPut, "/admin/loggers/github.com/acme/db", `{"level":"warn"}`)
+		Consistently(db.EffectiveLevel, 50*time.Millisecond, 5*time.Millisecond).
+			Should(Equal(WarnLevel))
+	})
+	ginkgo.It("status", func() {
+		rec := serve(http.MethodGet, "/admin/status", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		ctx.RealLogger("github.com/acme/db").AddWriter(&unhealthyWriter{})
+		rec = serve(http.MethodGet, "/admin/status", "")
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		var status adminStatus
+		decode(rec, &status)
+		Expect(status.Healthy).To(BeFalse())
+		Expect(status.Writers).To(ContainElement(writerStatus{
+			Name:    "UNHEALTHY",
+			Logger:  "github.com/acme/db",
+			Healthy: false,
+			Error:   "disk full",
+		}))
+	})
+})
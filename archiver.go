@@ -17,10 +17,12 @@ package lork
 import (
 	"archive/zip"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -39,6 +41,22 @@ func newArchiver(filenamePattern string) Archiver {
 	}
 }
 
+func init() {
+	RegisterArchiver("none", newArchiverFromConfig(func() Archiver { return &noneArchiver{} }))
+	RegisterArchiver("gzip", newArchiverFromConfig(func() Archiver { return &gzipArchiver{} }))
+	RegisterArchiver("zip", newArchiverFromConfig(func() Archiver { return &zipArchiver{} }))
+}
+
+// newArchiverFromConfig creates an ArchiverFactory for archivers without options.
+func newArchiverFromConfig(newArchiver func() Archiver) ArchiverFactory {
+	return func(d OptionDecoder) (Archiver, error) {
+		if err := d.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return newArchiver(), nil
+	}
+}
+
 type noneArchiver struct {
 }
 
@@ -47,6 +65,7 @@ func (a *noneArchiver) Archive(filename, archiveFilename string) error {
 }
 
 type gzipArchiver struct {
+	wg sync.WaitGroup
 }
 
 func (a *gzipArchiver) Archive(filename, archiveFilename string) error {
@@ -60,7 +79,9 @@ func (a *gzipArchiver) Archive(filename, archiveFilename string) error {
 		ModTime: time.Now(),
 		Name:    strings.TrimSuffix(archiveFilename, ".gz"),
 	}
+	a.wg.Add(1)
 	go func() {
+		defer a.wg.Done()
 		_, _ = io.Copy(w, origin)
 		_ = w.Close()
 		_ = origin.Close()
@@ -72,7 +93,13 @@ func (a *gzipArchiver) Archive(filename, archiveFilename string) error {
 	return nil
 }
 
+// Flush waits until all the in-flight archiving finished.
+func (a *gzipArchiver) Flush(ctx context.Context) error {
+	return waitGroupDone(ctx, &a.wg, "archiving in progress")
+}
+
 type zipArchiver struct {
+	wg sync.WaitGroup
 }
 
 func (a *zipArchiver) Archive(filename, archiveFilename string) error {
@@ -91,7 +118,9 @@ func (a *zipArchiver) Archive(filename, archiveFilename string) error {
 	if err != nil {
 		return err
 	}
+	a.wg.Add(1)
 	go func() {
+		defer a.wg.Done()
 		_, _ = io.Copy(w, origin)
 		_ = zw.Close()
 		_ = origin.Close()
@@ -103,6 +132,11 @@ func (a *zipArchiver) Archive(filename, archiveFilename string) error {
 	return nil
 }
 
+// Flush waits until all the in-flight archiving finished.
+func (a *zipArchiver) Flush(ctx context.Context) error {
+	return waitGroupDone(ctx, &a.wg, "archiving in progress")
+}
+
 func renameOpenFile(filename, archiveFilename string) (*os.File, *os.File, error) {
 	tmpFilename := fmt.Sprintf("%s-%v.%s", archiveFilename, time.Now().UnixNano(), "tmp")
 	if err := rename(filename, tmpFilename); err != nil {
@@ -15,8 +15,11 @@
 package lork
 
 import (
+	"compress/gzip"
+	"context"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/onsi/ginkgo/v2"
@@ -38,4 +41,27 @@ var _ = ginkgo.Describe("archiver", func() {
 		err = os.Remove(origin.Name())
 		Expect(err).To(BeNil())
 	})
+	ginkgo.It("flush gzip archiving", func() {
+		dir, err := os.MkdirTemp("", "lork-archiver")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		fn := filepath.Join(dir, "test.log")
+		Expect(os.WriteFile(fn, []byte("ABC"), os.ModePerm)).To(BeNil())
+		archiver := newArchiver("test.log.gz")
+		Expect(archiver.Archive(fn, fn+".gz")).To(BeNil())
+		Expect(flush(context.Background(), archiver)).To(BeNil())
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).To(BeNil())
+		Expect(entries).To(HaveLen(1))
+		f, err := os.Open(fn + ".gz")
+		Expect(err).To(BeNil())
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		Expect(err).To(BeNil())
+		c, err := io.ReadAll(r)
+		Expect(err).To(BeNil())
+		Expect(string(c)).To(Equal("ABC"))
+	})
 })
@@ -15,7 +15,11 @@
 package lork
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 type AsyncWriter struct {
@@ -23,6 +27,9 @@ type AsyncWriter struct {
 	locker      sync.Mutex
 	queue       *BlockingQueue
 	isRunning   bool
+	pending     int64
+	done        chan struct{}
+	abort       chan struct{}
 	multiWriter *MultiWriter
 }
 
@@ -49,32 +56,123 @@ func NewAsyncWriter(options ...func(*AsyncWriterOption)) *AsyncWriter {
 	}
 }
 
+func init() {
+	RegisterWriter("async", newAsyncWriterFromConfig)
+}
+
+type asyncWriterConfig struct {
+	QueueSize int      `json:"queue_size"`
+	Writers   []string `json:"writers"`
+}
+
+func newAsyncWriterFromConfig(d OptionDecoder) (Writer, error) {
+	var c asyncWriterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if c.QueueSize < 0 {
+		return nil, fmt.Errorf("invalid queue_size %d", c.QueueSize)
+	}
+	if len(c.Writers) == 0 {
+		return nil, errors.New("async writer needs writers")
+	}
+	writers, err := d.Writers(c.Writers)
+	if err != nil {
+		return nil, err
+	}
+
+	aw := NewAsyncWriter(func(o *AsyncWriterOption) {
+		o.Name = d.Name()
+		if c.QueueSize > 0 {
+			o.QueueSize = c.QueueSize
+		}
+	})
+	d.OnApply(func() error {
+		return aw.multiWriter.addWriterChecked(writers...)
+	})
+
+	return aw, nil
+}
+
 func (w *AsyncWriter) Start() {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
 	if w.isRunning {
 		return
 	}
 	w.isRunning = true
-	go w.startWorker()
+	w.done = make(chan struct{})
+	w.abort = make(chan struct{})
+	go w.startWorker(w.done, w.abort)
 }
 
+// Stop stops the worker after all the queued events are written, then stops
+// all the writers attached.
 func (w *AsyncWriter) Stop() {
-	w.locker.Lock()
-	defer w.locker.Unlock()
-	w.multiWriter.ResetWriter()
+	w.StopContext(context.Background())
+}
 
+// StopContext stops the worker after all the queued events are written or ctx is
+// done, then stops all the writers attached. The events not written will be dropped.
+func (w *AsyncWriter) StopContext(ctx context.Context) {
+	w.locker.Lock()
+	isRunning, done, abort := w.isRunning, w.done, w.abort
 	w.isRunning = false
+	w.locker.Unlock()
+
+	if isRunning {
+		// nil is the signal for worker to exit after the queued events
+		w.queue.Put(nil)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// the worker may be blocked by a writer, which will be unblocked
+			// when the writers are stopped
+			close(abort)
+		}
+	}
+	w.multiWriter.resetWriter(ctx)
+}
+
+// Flush blocks until all the queued events are written, and then flushes the
+// writers attached.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	if err := waitPending(ctx, &w.pending); err != nil {
+		return err
+	}
+
+	return w.multiWriter.Flush(ctx)
+}
+
+// Health reports unhealthy if this writer is not running or the queue is full, or
+// any writer attached is unhealthy.
+func (w *AsyncWriter) Health() error {
+	w.locker.Lock()
+	isRunning := w.isRunning
+	w.locker.Unlock()
+
+	if !isRunning {
+		return errors.New("async writer is not running")
+	}
+	if w.queue.RemainCapacity() <= 16 {
+		return fmt.Errorf("queue is full, %d events pending", atomic.LoadInt64(&w.pending))
+	}
+
+	return w.multiWriter.Health()
 }
 
 func (w *AsyncWriter) DoWrite(event *LogEvent) error {
 	w.locker.Lock()
 	defer w.locker.Unlock()
 
-	if w.queue.RemainCapacity() <= 16 {
+	if !w.isRunning || w.queue.RemainCapacity() <= 16 {
 		// discard
 		return nil
 	}
 
 	// copy a log event for further usage
+	atomic.AddInt64(&w.pending, 1)
 	w.queue.Put(event.Copy())
 
 	return nil
@@ -100,15 +198,25 @@ func (w *AsyncWriter) ResetWriter() {
 	w.multiWriter.ResetWriter()
 }
 
-func (w *AsyncWriter) startWorker() {
+func (w *AsyncWriter) startWorker(done, abort chan struct{}) {
+	defer close(done)
+
 	for {
-		if !w.isRunning {
+		p, ok := (w.queue.Take()).(*LogEvent)
+		if !ok {
 			break
 		}
 
-		p := (w.queue.Take()).(*LogEvent)
+		select {
+		case <-abort:
+			// drop the queued events after stopping is aborted
+			atomic.AddInt64(&w.pending, -1)
+			continue
+		default:
+		}
 		if err := w.multiWriter.WriteEvent(p); err != nil {
 			Reportf("async writer write error: %v", err)
 		}
+		atomic.AddInt64(&w.pending, -1)
 	}
 }
@@ -0,0 +1,112 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type gatedWriter struct {
+	gate     chan struct{}
+	messages []string
+	written  int
+	stopped  int32
+}
+
+func (w *gatedWriter) Name() string {
+	return "GATED"
+}
+
+func (w *gatedWriter) DoWrite(event *LogEvent) error {
+	<-w.gate
+	w.messages = append(w.messages, string(event.Message()))
+	return nil
+}
+
+func (w *gatedWriter) Start() {
+}
+
+func (w *gatedWriter) Stop() {
+	w.written = len(w.messages)
+	atomic.StoreInt32(&w.stopped, 1)
+}
+
+var _ = ginkgo.Describe("async writer", func() {
+	var ctx *LoggerContext
+	var gated *gatedWriter
+
+	ginkgo.BeforeEach(func() {
+		gated = &gatedWriter{gate: make(chan struct{})}
+		aw := NewAsyncWriter(func(o *AsyncWriterOption) {
+			o.Name = "ASYNC"
+		})
+		aw.AddWriter(gated)
+		ctx = NewLoggerContext(NewClassicLogger)
+		ctx.RealLogger(RootLoggerName).AddWriter(aw)
+	})
+
+	ginkgo.It("flush with deadline", func() {
+		logger := ctx.Logger("github.com/coolerfall/lork")
+		logger.Info().Msg("first")
+		logger.Info().Msg("second")
+
+		timeout, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := ctx.Flush(timeout)
+		var fe *FlushError
+		Expect(errors.As(err, &fe)).To(BeTrue())
+		Expect(fe.Writers).To(Equal([]string{"ASYNC"}))
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("events pending"))
+
+		close(gated.gate)
+		Expect(ctx.Flush(context.Background())).To(BeNil())
+		Expect(gated.messages).To(Equal([]string{"first", "second"}))
+	})
+	ginkgo.It("shutdown drains before stopping", func() {
+		logger := ctx.Logger("github.com/coolerfall/lork")
+		for i := 0; i < 10; i++ {
+			logger.Info().Msg("drain")
+		}
+		close(gated.gate)
+
+		Expect(ctx.Shutdown(context.Background())).To(BeNil())
+		Expect(gated.messages).To(HaveLen(10))
+		Expect(gated.written).To(Equal(10))
+
+		logger.Info().Msg("after shutdown")
+		Expect(gated.messages).To(HaveLen(10))
+	})
+	ginkgo.It("shutdown with deadline when writer blocks", func() {
+		logger := ctx.Logger("github.com/coolerfall/lork")
+		logger.Info().Msg("first")
+		logger.Info().Msg("second")
+
+		timeout, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := ctx.Shutdown(timeout)
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Eventually(func() int32 {
+			return atomic.LoadInt32(&gated.stopped)
+		}).Should(Equal(int32(1)))
+		close(gated.gate)
+	})
+})
@@ -35,6 +35,7 @@ var (
 type logrusLogger struct {
 	name        string
 	multiWriter *lork.MultiWriter
+	entry       *logrus.Entry
 }
 
 // NewLogrusLogger creates a new instance of logrusLogger used to be bound to lork.
@@ -74,36 +75,55 @@ func (l *logrusLogger) SetLevel(lvl lork.Level) {
 	logrus.SetLevel(lorkLvlToLogrusLvl[lvl])
 }
 
+func (l *logrusLogger) SetCaller(bool, int) {
+}
+
+func (l *logrusLogger) SetStackLevel(lork.Level) {
+}
+
+func (l *logrusLogger) With(fields func(lork.Record)) lork.ILogger {
+	r := newLogrusRecord(l.newEntry(), logrus.InfoLevel)
+	fields(r)
+	entry := r.entry
+	logrusRecordPool.Put(r)
+
+	return &logrusLogger{
+		name:        l.name,
+		multiWriter: l.multiWriter,
+		entry:       entry,
+	}
+}
+
 func (l *logrusLogger) Trace() lork.Record {
-	return newLogrusRecord(logrus.TraceLevel)
+	return newLogrusRecord(l.newEntry(), logrus.TraceLevel)
 }
 
 func (l *logrusLogger) Debug() lork.Record {
-	return newLogrusRecord(logrus.DebugLevel)
+	return newLogrusRecord(l.newEntry(), logrus.DebugLevel)
 }
 
 func (l *logrusLogger) Info() lork.Record {
-	return newLogrusRecord(logrus.InfoLevel)
+	return newLogrusRecord(l.newEntry(), logrus.InfoLevel)
 }
 
 func (l *logrusLogger) Warn() lork.Record {
-	return newLogrusRecord(logrus.WarnLevel)
+	return newLogrusRecord(l.newEntry(), logrus.WarnLevel)
 }
 
 func (l *logrusLogger) Error() lork.Record {
-	return newLogrusRecord(logrus.ErrorLevel)
+	return newLogrusRecord(l.newEntry(), logrus.ErrorLevel)
 }
 
 func (l *logrusLogger) Fatal() lork.Record {
-	return newLogrusRecord(logrus.FatalLevel)
+	return newLogrusRecord(l.newEntry(), logrus.FatalLevel)
 }
 
 func (l *logrusLogger) Panic() lork.Record {
-	return newLogrusRecord(logrus.PanicLevel)
+	return newLogrusRecord(l.newEntry(), logrus.PanicLevel)
 }
 
 func (l *logrusLogger) Level(lvl lork.Level) lork.Record {
-	return newLogrusRecord(lorkLvlToLogrusLvl[lvl])
+	return newLogrusRecord(l.newEntry(), lorkLvlToLogrusLvl[lvl])
 }
 
 func (l *logrusLogger) Event(e *lork.LogEvent) {
@@ -111,3 +131,12 @@ func (l *logrusLogger) Event(e *lork.LogEvent) {
 		l.Error().Err(err).Msg("write raw event error")
 	}
 }
+
+// newEntry creates a logrus entry with bound fields.
+func (l *logrusLogger) newEntry() *logrus.Entry {
+	if l.entry != nil {
+		return l.entry
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger())
+}
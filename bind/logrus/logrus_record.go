@@ -15,6 +15,9 @@
 package logrus
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
@@ -31,14 +34,18 @@ var (
 )
 
 type logrusRecord struct {
-	entry *logrus.Entry
-	level logrus.Level
+	entry      *logrus.Entry
+	level      logrus.Level
+	callerSkip int
+	withStack  bool
 }
 
-func newLogrusRecord(lvl logrus.Level) *logrusRecord {
+func newLogrusRecord(entry *logrus.Entry, lvl logrus.Level) *logrusRecord {
 	r := logrusRecordPool.Get().(*logrusRecord)
-	r.entry = logrus.NewEntry(logrus.StandardLogger())
+	r.entry = entry
 	r.level = lvl
+	r.callerSkip = -1
+	r.withStack = false
 
 	return r
 }
@@ -59,13 +66,12 @@ func (r *logrusRecord) Bytes(key string, val []byte) lork.Record {
 }
 
 func (r *logrusRecord) Err(err error) lork.Record {
-	r.entry = r.entry.WithError(err)
+	lork.EncodeError(r, err)
 	return r
 }
 
 func (r *logrusRecord) Errs(key string, errs []error) lork.Record {
-	r.entry = r.entry.WithField(key, errs)
-	return r
+	return r.Array(key, lork.ErrorArray(errs))
 }
 
 func (r *logrusRecord) Bool(key string, val bool) lork.Record {
@@ -219,20 +225,101 @@ func (r *logrusRecord) Durs(key string, val []time.Duration) lork.Record {
 }
 
 func (r *logrusRecord) Any(key string, val interface{}) lork.Record {
+	if valuer, ok := val.(lork.LogValuer); ok {
+		val = lork.NewLazyMarshaler(valuer)
+	}
 	r.entry = r.entry.WithField(key, val)
 	return r
 }
 
+func (r *logrusRecord) Dict(key string, dict func(r lork.Record)) lork.Record {
+	r.entry = r.entry.WithField(key, json.RawMessage(lork.MarshalDict(dict)))
+	return r
+}
+
+func (r *logrusRecord) Object(key string, obj lork.ObjectMarshaler) lork.Record {
+	r.entry = r.entry.WithField(key, json.RawMessage(lork.MarshalObject(obj)))
+	return r
+}
+
+func (r *logrusRecord) Array(key string, arr lork.ArrayMarshaler) lork.Record {
+	r.entry = r.entry.WithField(key, json.RawMessage(lork.MarshalArray(arr)))
+	return r
+}
+
+func (r *logrusRecord) Ctx(ctx context.Context) lork.Record {
+	lork.ExtractContext(ctx, r)
+	return r
+}
+
+func (r *logrusRecord) Caller(skip int) lork.Record {
+	r.callerSkip = skip
+	return r
+}
+
+func (r *logrusRecord) Stack() lork.Record {
+	r.withStack = true
+	return r
+}
+
 func (r *logrusRecord) Msge() {
-	r.Msg("")
+	r.write("")
 }
 
 func (r *logrusRecord) Msg(msg string) {
-	r.entry.Log(r.level, msg)
-	logrusRecordPool.Put(r)
+	r.write(msg)
 }
 
 func (r *logrusRecord) Msgf(format string, v ...interface{}) {
-	r.entry.Logf(r.level, format, v...)
+	r.write(fmt.Sprintf(format, v...))
+}
+
+// write logs with logrus entry, this must be called by Msg, Msgf and Msge
+// directly to keep the depth of caller.
+func (r *logrusRecord) write(msg string) {
+	// skip write and Msg
+	skip := 2
+	if r.callerSkip > 0 {
+		skip += r.callerSkip
+	}
+	if r.callerSkip >= 0 {
+		if caller, fn, ok := lork.CallerFrame(skip); ok {
+			r.entry = r.entry.WithFields(logrus.Fields{
+				lork.CallerFieldKey:   caller,
+				lork.FunctionFieldKey: fn,
+			})
+		}
+	}
+	if r.withStack {
+		stack := lork.StackTrace(skip)
+		r.entry = r.entry.WithField(lork.StackFieldKey, json.RawMessage(stack))
+	}
+
+	r.log(msg)
+
+	lvl := r.level
 	logrusRecordPool.Put(r)
+
+	switch lvl {
+	case logrus.FatalLevel:
+		lork.Terminate(lork.FatalLevel, msg)
+	case logrus.PanicLevel:
+		lork.Terminate(lork.PanicLevel, msg)
+	}
+}
+
+// log logs with logrus entry, and recovers the panic of logrus for PanicLevel
+// so that writers can be flushed before panicking.
+func (r *logrusRecord) log(msg string) {
+	if r.level == logrus.PanicLevel {
+		defer func() {
+			if p := recover(); p != nil {
+				if _, ok := p.(*logrus.Entry); !ok {
+					panic(p)
+				}
+			}
+		}()
+	}
+
+	r.entry.Log(r.level, msg)
 }
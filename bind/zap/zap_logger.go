@@ -15,6 +15,7 @@
 package zap
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/coolerfall/lork"
@@ -39,6 +40,14 @@ type zapLogger struct {
 	name        string
 	atomicLevel zap.AtomicLevel
 	multiWriter *lork.MultiWriter
+	fields      []zapcore.Field
+	bound       atomic.Value
+}
+
+// boundLogger is the global zap logger with bound fields.
+type boundLogger struct {
+	global *zap.Logger
+	logger *zap.Logger
 }
 
 // NewZapLogger creates a new instance of zapLogger used to be bound to lork.
@@ -84,36 +93,58 @@ func (l *zapLogger) SetLevel(lvl lork.Level) {
 	l.atomicLevel.SetLevel(lorkLvlToZapLvl[lvl])
 }
 
+func (l *zapLogger) SetCaller(bool, int) {
+}
+
+func (l *zapLogger) SetStackLevel(lork.Level) {
+}
+
+// With collects the fields only, they will be bound to the current global zap logger
+// when logging, so the logger always writes with the latest core.
+func (l *zapLogger) With(fields func(lork.Record)) lork.ILogger {
+	r := newZapRecord(zap.New(&fieldsCore{fields: l.fields}), zapcore.InfoLevel)
+	fields(r)
+	core := r.logger.Core().(*fieldsCore)
+	zapRecordPool.Put(r)
+
+	return &zapLogger{
+		name:        l.name,
+		atomicLevel: l.atomicLevel,
+		multiWriter: l.multiWriter,
+		fields:      core.fields,
+	}
+}
+
 func (l *zapLogger) Trace() lork.Record {
 	return l.Debug()
 }
 
 func (l *zapLogger) Debug() lork.Record {
-	return newZapRecord(zapcore.DebugLevel)
+	return newZapRecord(l.zap(), zapcore.DebugLevel)
 }
 
 func (l *zapLogger) Info() lork.Record {
-	return newZapRecord(zapcore.InfoLevel)
+	return newZapRecord(l.zap(), zapcore.InfoLevel)
 }
 
 func (l *zapLogger) Warn() lork.Record {
-	return newZapRecord(zapcore.WarnLevel)
+	return newZapRecord(l.zap(), zapcore.WarnLevel)
 }
 
 func (l *zapLogger) Error() lork.Record {
-	return newZapRecord(zapcore.ErrorLevel)
+	return newZapRecord(l.zap(), zapcore.ErrorLevel)
 }
 
 func (l *zapLogger) Fatal() lork.Record {
-	return newZapRecord(zapcore.FatalLevel)
+	return newZapRecord(l.zap(), zapcore.FatalLevel)
 }
 
 func (l *zapLogger) Panic() lork.Record {
-	return newZapRecord(zapcore.PanicLevel)
+	return newZapRecord(l.zap(), zapcore.PanicLevel)
 }
 
 func (l *zapLogger) Level(lvl lork.Level) lork.Record {
-	return newZapRecord(lorkLvlToZapLvl[lvl])
+	return newZapRecord(l.zap(), lorkLvlToZapLvl[lvl])
 }
 
 func (l *zapLogger) Event(e *lork.LogEvent) {
@@ -122,6 +153,52 @@ func (l *zapLogger) Event(e *lork.LogEvent) {
 	}
 }
 
+// zap gets the global zap logger with bound fields, or the global one if no field
+// bound. The fields are bound again once the global logger is replaced.
+func (l *zapLogger) zap() *zap.Logger {
+	global := zap.L()
+	if len(l.fields) == 0 {
+		return global
+	}
+
+	if bound, ok := l.bound.Load().(*boundLogger); ok && bound.global == global {
+		return bound.logger
+	}
+	logger := global.With(l.fields...)
+	l.bound.Store(&boundLogger{global: global, logger: logger})
+
+	return logger
+}
+
+// fieldsCore is a zapcore.Core which only collects the fields added with With.
+type fieldsCore struct {
+	fields []zapcore.Field
+}
+
+func (c *fieldsCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *fieldsCore) With(fields []zapcore.Field) zapcore.Core {
+	// always copy to keep the fields of parent unchanged
+	collected := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	collected = append(append(collected, c.fields...), fields...)
+
+	return &fieldsCore{fields: collected}
+}
+
+func (c *fieldsCore) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+func (c *fieldsCore) Write(zapcore.Entry, []zapcore.Field) error {
+	return nil
+}
+
+func (c *fieldsCore) Sync() error {
+	return nil
+}
+
 func rf3339Encoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendString(t.Format(lork.TimestampFormat))
 }
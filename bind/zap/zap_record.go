@@ -15,6 +15,9 @@
 package zap
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
@@ -32,14 +35,18 @@ var (
 )
 
 type zapRecord struct {
-	logger *zap.Logger
-	level  zapcore.Level
+	logger     *zap.Logger
+	level      zapcore.Level
+	callerSkip int
+	withStack  bool
 }
 
-func newZapRecord(lvl zapcore.Level) *zapRecord {
+func newZapRecord(logger *zap.Logger, lvl zapcore.Level) *zapRecord {
 	r := zapRecordPool.Get().(*zapRecord)
-	r.logger = zap.L()
+	r.logger = logger
 	r.level = lvl
+	r.callerSkip = -1
+	r.withStack = false
 
 	return r
 }
@@ -60,13 +67,12 @@ func (r *zapRecord) Bytes(key string, val []byte) lork.Record {
 }
 
 func (r *zapRecord) Err(err error) lork.Record {
-	r.logger = r.logger.With(zap.Error(err))
+	lork.EncodeError(r, err)
 	return r
 }
 
 func (r *zapRecord) Errs(key string, errs []error) lork.Record {
-	r.logger = r.logger.With(zap.Errors(key, errs))
-	return r
+	return r.Array(key, lork.ErrorArray(errs))
 }
 
 func (r *zapRecord) Bool(key string, b bool) lork.Record {
@@ -220,16 +226,80 @@ func (r *zapRecord) Durs(key string, val []time.Duration) lork.Record {
 }
 
 func (r *zapRecord) Any(key string, val interface{}) lork.Record {
+	if valuer, ok := val.(lork.LogValuer); ok {
+		// zap encodes fields once added, so only resolve lazy value when enabled
+		if r.logger.Core().Enabled(r.level) {
+			r.logger = r.logger.With(zap.Reflect(key, lork.NewLazyMarshaler(valuer)))
+		}
+		return r
+	}
+
 	r.logger = r.logger.With(zap.Any(key, val))
 
 	return r
 }
 
+func (r *zapRecord) Dict(key string, dict func(r lork.Record)) lork.Record {
+	r.logger = r.logger.With(zap.Reflect(key, json.RawMessage(lork.MarshalDict(dict))))
+	return r
+}
+
+func (r *zapRecord) Object(key string, obj lork.ObjectMarshaler) lork.Record {
+	r.logger = r.logger.With(zap.Reflect(key, json.RawMessage(lork.MarshalObject(obj))))
+	return r
+}
+
+func (r *zapRecord) Array(key string, arr lork.ArrayMarshaler) lork.Record {
+	r.logger = r.logger.With(zap.Reflect(key, json.RawMessage(lork.MarshalArray(arr))))
+	return r
+}
+
+func (r *zapRecord) Ctx(ctx context.Context) lork.Record {
+	lork.ExtractContext(ctx, r)
+	return r
+}
+
+func (r *zapRecord) Caller(skip int) lork.Record {
+	r.callerSkip = skip
+	return r
+}
+
+func (r *zapRecord) Stack() lork.Record {
+	r.withStack = true
+	return r
+}
+
 func (r *zapRecord) Msge() {
-	r.Msg("")
+	r.write("")
 }
 
 func (r *zapRecord) Msg(msg string) {
+	r.write(msg)
+}
+
+func (r *zapRecord) Msgf(format string, v ...interface{}) {
+	r.write(fmt.Sprintf(format, v...))
+}
+
+// write logs with zap logger, this must be called by Msg, Msgf and Msge
+// directly to keep the depth of caller.
+func (r *zapRecord) write(msg string) {
+	// skip write and Msg
+	skip := 2
+	if r.callerSkip > 0 {
+		skip += r.callerSkip
+	}
+	if r.callerSkip >= 0 {
+		if caller, fn, ok := lork.CallerFrame(skip); ok {
+			r.logger = r.logger.With(zap.String(lork.CallerFieldKey, caller),
+				zap.String(lork.FunctionFieldKey, fn))
+		}
+	}
+	if r.withStack {
+		stack := lork.StackTrace(skip)
+		r.logger = r.logger.With(zap.Reflect(lork.StackFieldKey, json.RawMessage(stack)))
+	}
+
 	switch r.level {
 	case zapcore.DebugLevel:
 		r.logger.Debug(msg)
@@ -239,32 +309,21 @@ func (r *zapRecord) Msg(msg string) {
 		r.logger.Warn(msg)
 	case zapcore.ErrorLevel:
 		r.logger.Error(msg)
-	case zapcore.FatalLevel:
-		r.logger.Fatal(msg)
-	case zapcore.PanicLevel:
-		r.logger.Panic(msg)
+	case zapcore.FatalLevel, zapcore.PanicLevel:
+		// write with core directly, zap will exit or panic before writers are flushed
+		ent := zapcore.Entry{Level: r.level, Time: time.Now(), Message: msg}
+		if ce := r.logger.Core().Check(ent, nil); ce != nil {
+			ce.Write()
+		}
 	}
 
+	lvl := r.level
 	zapRecordPool.Put(r)
-}
-
-func (r *zapRecord) Msgf(format string, v ...interface{}) {
-	sl := r.logger.Sugar()
 
-	switch r.level {
-	case zapcore.DebugLevel:
-		sl.Debugf(format, v...)
-	case zapcore.InfoLevel:
-		sl.Infof(format, v...)
-	case zapcore.WarnLevel:
-		sl.Warnf(format, v...)
-	case zapcore.ErrorLevel:
-		sl.Errorf(format, v...)
+	switch lvl {
 	case zapcore.FatalLevel:
-		sl.Fatalf(format, v...)
+		lork.Terminate(lork.FatalLevel, msg)
 	case zapcore.PanicLevel:
-		sl.Panicf(format, v...)
+		lork.Terminate(lork.PanicLevel, msg)
 	}
-
-	zapRecordPool.Put(r)
 }
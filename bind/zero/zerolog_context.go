@@ -0,0 +1,254 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zero
+
+import (
+	"context"
+	"time"
+
+	"github.com/coolerfall/lork"
+	"github.com/rs/zerolog"
+)
+
+// zeroContext is a lork.Record which adds fields into zerolog context, it's used
+// to create child logger with fields.
+type zeroContext struct {
+	context zerolog.Context
+}
+
+func newZeroContext(c zerolog.Context) *zeroContext {
+	return &zeroContext{
+		context: c,
+	}
+}
+
+func (c *zeroContext) Str(key, val string) lork.Record {
+	c.context = c.context.Str(key, val)
+	return c
+}
+
+func (c *zeroContext) Strs(key string, val []string) lork.Record {
+	c.context = c.context.Strs(key, val)
+	return c
+}
+
+func (c *zeroContext) Bytes(key string, val []byte) lork.Record {
+	c.context = c.context.Bytes(key, val)
+	return c
+}
+
+func (c *zeroContext) Err(err error) lork.Record {
+	lork.EncodeError(c, err)
+	return c
+}
+
+func (c *zeroContext) Errs(key string, errs []error) lork.Record {
+	return c.Array(key, lork.ErrorArray(errs))
+}
+
+func (c *zeroContext) Bool(key string, val bool) lork.Record {
+	c.context = c.context.Bool(key, val)
+	return c
+}
+
+func (c *zeroContext) Bools(key string, val []bool) lork.Record {
+	c.context = c.context.Bools(key, val)
+	return c
+}
+
+func (c *zeroContext) Int(key string, val int) lork.Record {
+	c.context = c.context.Int(key, val)
+	return c
+}
+
+func (c *zeroContext) Ints(key string, val []int) lork.Record {
+	c.context = c.context.Ints(key, val)
+	return c
+}
+
+func (c *zeroContext) Int8(key string, val int8) lork.Record {
+	c.context = c.context.Int8(key, val)
+	return c
+}
+
+func (c *zeroContext) Ints8(key string, val []int8) lork.Record {
+	c.context = c.context.Ints8(key, val)
+	return c
+}
+
+func (c *zeroContext) Int16(key string, val int16) lork.Record {
+	c.context = c.context.Int16(key, val)
+	return c
+}
+
+func (c *zeroContext) Ints16(key string, val []int16) lork.Record {
+	c.context = c.context.Ints16(key, val)
+	return c
+}
+
+func (c *zeroContext) Int32(key string, val int32) lork.Record {
+	c.context = c.context.Int32(key, val)
+	return c
+}
+
+func (c *zeroContext) Ints32(key string, val []int32) lork.Record {
+	c.context = c.context.Ints32(key, val)
+	return c
+}
+
+func (c *zeroContext) Int64(key string, val int64) lork.Record {
+	c.context = c.context.Int64(key, val)
+	return c
+}
+
+func (c *zeroContext) Ints64(key string, val []int64) lork.Record {
+	c.context = c.context.Ints64(key, val)
+	return c
+}
+
+func (c *zeroContext) Uint(key string, val uint) lork.Record {
+	c.context = c.context.Uint(key, val)
+	return c
+}
+
+func (c *zeroContext) Uints(key string, val []uint) lork.Record {
+	c.context = c.context.Uints(key, val)
+	return c
+}
+
+func (c *zeroContext) Uint8(key string, val uint8) lork.Record {
+	c.context = c.context.Uint8(key, val)
+	return c
+}
+
+func (c *zeroContext) Uints8(key string, val []uint8) lork.Record {
+	c.context = c.context.Uints8(key, val)
+	return c
+}
+
+func (c *zeroContext) Uint16(key string, val uint16) lork.Record {
+	c.context = c.context.Uint16(key, val)
+	return c
+}
+
+func (c *zeroContext) Uints16(key string, val []uint16) lork.Record {
+	c.context = c.context.Uints16(key, val)
+	return c
+}
+
+func (c *zeroContext) Uint32(key string, val uint32) lork.Record {
+	c.context = c.context.Uint32(key, val)
+	return c
+}
+
+func (c *zeroContext) Uints32(key string, val []uint32) lork.Record {
+	c.context = c.context.Uints32(key, val)
+	return c
+}
+
+func (c *zeroContext) Uint64(key string, val uint64) lork.Record {
+	c.context = c.context.Uint64(key, val)
+	return c
+}
+
+func (c *zeroContext) Uints64(key string, val []uint64) lork.Record {
+	c.context = c.context.Uints64(key, val)
+	return c
+}
+
+func (c *zeroContext) Float32(key string, val float32) lork.Record {
+	c.context = c.context.Float32(key, val)
+	return c
+}
+
+func (c *zeroContext) Floats32(key string, val []float32) lork.Record {
+	c.context = c.context.Floats32(key, val)
+	return c
+}
+
+func (c *zeroContext) Float64(key string, val float64) lork.Record {
+	c.context = c.context.Float64(key, val)
+	return c
+}
+
+func (c *zeroContext) Floats64(key string, val []float64) lork.Record {
+	c.context = c.context.Floats64(key, val)
+	return c
+}
+
+func (c *zeroContext) Time(key string, val time.Time) lork.Record {
+	c.context = c.context.Time(key, val)
+	return c
+}
+
+func (c *zeroContext) Times(key string, val []time.Time) lork.Record {
+	c.context = c.context.Times(key, val)
+	return c
+}
+
+func (c *zeroContext) Dur(key string, val time.Duration) lork.Record {
+	c.context = c.context.Dur(key, val)
+	return c
+}
+
+func (c *zeroContext) Durs(key string, val []time.Duration) lork.Record {
+	c.context = c.context.Durs(key, val)
+	return c
+}
+
+func (c *zeroContext) Any(key string, val interface{}) lork.Record {
+	if valuer, ok := val.(lork.LogValuer); ok {
+		val = lork.NewLazyMarshaler(valuer)
+	}
+	c.context = c.context.Interface(key, val)
+	return c
+}
+
+func (c *zeroContext) Dict(key string, dict func(r lork.Record)) lork.Record {
+	c.context = c.context.RawJSON(key, lork.MarshalDict(dict))
+	return c
+}
+
+func (c *zeroContext) Object(key string, obj lork.ObjectMarshaler) lork.Record {
+	c.context = c.context.RawJSON(key, lork.MarshalObject(obj))
+	return c
+}
+
+func (c *zeroContext) Array(key string, arr lork.ArrayMarshaler) lork.Record {
+	c.context = c.context.RawJSON(key, lork.MarshalArray(arr))
+	return c
+}
+
+func (c *zeroContext) Ctx(ctx context.Context) lork.Record {
+	lork.ExtractContext(ctx, c)
+	return c
+}
+
+func (c *zeroContext) Caller(_ int) lork.Record {
+	return c
+}
+
+func (c *zeroContext) Stack() lork.Record {
+	return c
+}
+
+func (c *zeroContext) Msge() {
+}
+
+func (c *zeroContext) Msg(_ string) {
+}
+
+func (c *zeroContext) Msgf(_ string, _ ...interface{}) {
+}
@@ -74,36 +74,55 @@ func (l *zeroLogger) SetLevel(lvl lork.Level) {
 	zerolog.SetGlobalLevel(lorkLvlToZeroLvl[lvl])
 }
 
+func (l *zeroLogger) SetCaller(bool, int) {
+}
+
+func (l *zeroLogger) SetStackLevel(lork.Level) {
+}
+
+func (l *zeroLogger) With(fields func(lork.Record)) lork.ILogger {
+	c := newZeroContext(l.logger.With())
+	fields(c)
+
+	return &zeroLogger{
+		name:        l.name,
+		logger:      c.context.Logger(),
+		multiWriter: l.multiWriter,
+	}
+}
+
 func (l *zeroLogger) Trace() lork.Record {
-	return newZeroRecord(l.logger.Trace())
+	return newZeroRecord(l.logger.Trace(), lork.TraceLevel)
 }
 
 func (l *zeroLogger) Debug() lork.Record {
-	return newZeroRecord(l.logger.Debug())
+	return newZeroRecord(l.logger.Debug(), lork.DebugLevel)
 }
 
 func (l *zeroLogger) Info() lork.Record {
-	return newZeroRecord(l.logger.Info())
+	return newZeroRecord(l.logger.Info(), lork.InfoLevel)
 }
 
 func (l *zeroLogger) Warn() lork.Record {
-	return newZeroRecord(l.logger.Warn())
+	return newZeroRecord(l.logger.Warn(), lork.WarnLevel)
 }
 
 func (l *zeroLogger) Error() lork.Record {
-	return newZeroRecord(l.logger.Error())
+	return newZeroRecord(l.logger.Error(), lork.ErrorLevel)
 }
 
 func (l *zeroLogger) Fatal() lork.Record {
-	return newZeroRecord(l.logger.Fatal())
+	// zerolog will not exit with WithLevel, lork will exit after flushing writers
+	return newZeroRecord(l.logger.WithLevel(zerolog.FatalLevel), lork.FatalLevel)
 }
 
 func (l *zeroLogger) Panic() lork.Record {
-	return newZeroRecord(l.logger.Panic())
+	// zerolog will not panic with WithLevel, lork will panic after flushing writers
+	return newZeroRecord(l.logger.WithLevel(zerolog.PanicLevel), lork.PanicLevel)
 }
 
 func (l *zeroLogger) Level(lvl lork.Level) lork.Record {
-	return newZeroRecord(l.logger.WithLevel(lorkLvlToZeroLvl[lvl]))
+	return newZeroRecord(l.logger.WithLevel(lorkLvlToZeroLvl[lvl]), lvl)
 }
 
 func (l *zeroLogger) Event(e *lork.LogEvent) {
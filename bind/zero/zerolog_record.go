@@ -15,6 +15,8 @@
 package zero
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -31,12 +33,18 @@ var (
 )
 
 type zeroRecord struct {
-	event *zerolog.Event
+	event      *zerolog.Event
+	level      lork.Level
+	callerSkip int
+	withStack  bool
 }
 
-func newZeroRecord(e *zerolog.Event) *zeroRecord {
+func newZeroRecord(e *zerolog.Event, lvl lork.Level) *zeroRecord {
 	r := zeroRecordPool.Get().(*zeroRecord)
 	r.event = e
+	r.level = lvl
+	r.callerSkip = -1
+	r.withStack = false
 	return r
 }
 
@@ -56,13 +64,12 @@ func (r *zeroRecord) Bytes(key string, val []byte) lork.Record {
 }
 
 func (r *zeroRecord) Err(err error) lork.Record {
-	r.event.Err(err)
+	lork.EncodeError(r, err)
 	return r
 }
 
 func (r *zeroRecord) Errs(key string, errs []error) lork.Record {
-	r.event.Errs(key, errs)
-	return r
+	return r.Array(key, lork.ErrorArray(errs))
 }
 
 func (r *zeroRecord) Bool(key string, val bool) lork.Record {
@@ -216,20 +223,75 @@ func (r *zeroRecord) Durs(key string, val []time.Duration) lork.Record {
 }
 
 func (r *zeroRecord) Any(key string, val interface{}) lork.Record {
+	if valuer, ok := val.(lork.LogValuer); ok {
+		val = lork.NewLazyMarshaler(valuer)
+	}
 	r.event.Interface(key, val)
 	return r
 }
 
+func (r *zeroRecord) Dict(key string, dict func(r lork.Record)) lork.Record {
+	r.event.RawJSON(key, lork.MarshalDict(dict))
+	return r
+}
+
+func (r *zeroRecord) Object(key string, obj lork.ObjectMarshaler) lork.Record {
+	r.event.RawJSON(key, lork.MarshalObject(obj))
+	return r
+}
+
+func (r *zeroRecord) Array(key string, arr lork.ArrayMarshaler) lork.Record {
+	r.event.RawJSON(key, lork.MarshalArray(arr))
+	return r
+}
+
+func (r *zeroRecord) Ctx(ctx context.Context) lork.Record {
+	lork.ExtractContext(ctx, r)
+	return r
+}
+
+func (r *zeroRecord) Caller(skip int) lork.Record {
+	r.callerSkip = skip
+	return r
+}
+
+func (r *zeroRecord) Stack() lork.Record {
+	r.withStack = true
+	return r
+}
+
 func (r *zeroRecord) Msge() {
-	r.Msg("")
+	r.write("")
 }
 
 func (r *zeroRecord) Msg(msg string) {
-	r.event.Msg(msg)
-	zeroRecordPool.Put(r)
+	r.write(msg)
 }
 
 func (r *zeroRecord) Msgf(format string, v ...interface{}) {
-	r.event.Msgf(format, v...)
+	r.write(fmt.Sprintf(format, v...))
+}
+
+// write sends the zerolog event, this must be called by Msg, Msgf and Msge
+// directly to keep the depth of caller.
+func (r *zeroRecord) write(msg string) {
+	// skip write and Msg
+	skip := 2
+	if r.callerSkip > 0 {
+		skip += r.callerSkip
+	}
+	if r.callerSkip >= 0 {
+		if caller, fn, ok := lork.CallerFrame(skip); ok {
+			r.event.Str(lork.CallerFieldKey, caller).Str(lork.FunctionFieldKey, fn)
+		}
+	}
+	if r.withStack {
+		r.event.RawJSON(lork.StackFieldKey, lork.StackTrace(skip))
+	}
+
+	r.event.Msg(msg)
+
+	lvl := r.level
 	zeroRecordPool.Put(r)
+	lork.Terminate(lvl, msg)
 }
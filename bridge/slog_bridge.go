@@ -0,0 +1,121 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package bridge
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/coolerfall/lork"
+)
+
+type slogBridge struct {
+}
+
+// SlogBridgeOption represents available options for slog bridge.
+type SlogBridgeOption struct {
+	// Level is the minimum level of slog records.
+	Level slog.Leveler
+	// AddSource adds the caller of slog records if true.
+	AddSource bool
+}
+
+// NewSlogBridge creates a new lork bridge for log/slog, and the bridge will be set
+// as the default slog logger. The logger name can be set with an attribute keyed
+// by lork.LoggerNameFieldKey, the name of bridge will be used if not set.
+func NewSlogBridge(options ...func(*SlogBridgeOption)) lork.Bridge {
+	opts := &SlogBridgeOption{
+		Level: slog.LevelDebug,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	bridge := &slogBridge{}
+	handler := slog.NewJSONHandler(bridge, &slog.HandlerOptions{
+		AddSource:   opts.AddSource,
+		Level:       opts.Level,
+		ReplaceAttr: replaceSlogAttr,
+	})
+	slog.SetDefault(slog.New(handler))
+
+	return bridge
+}
+
+// Name returns "log/slog". Lork checks the cycle of bridge and provider by name, and
+// no provider is named "log/slog" since lork doesn't provide one writing into slog,
+// so the check never fires unless such a provider is loaded.
+func (b *slogBridge) Name() string {
+	return "log/slog"
+}
+
+func (b *slogBridge) RouteByLoggerName() bool {
+	return true
+}
+
+func (b *slogBridge) ParseLevel(lvl string) lork.Level {
+	var level slog.Level
+	if err := (&level).UnmarshalText([]byte(lvl)); err != nil {
+		lork.Reportf("parse slog level error: %s", err)
+	}
+
+	switch {
+	case level < slog.LevelDebug:
+		return lork.TraceLevel
+	case level < slog.LevelInfo:
+		return lork.DebugLevel
+	case level < slog.LevelWarn:
+		return lork.InfoLevel
+	case level < slog.LevelError:
+		return lork.WarnLevel
+	default:
+		return lork.ErrorLevel
+	}
+}
+
+func (b *slogBridge) Write(p []byte) (int, error) {
+	lork.BridgeWrite(b, p)
+
+	return len(p), nil
+}
+
+// replaceSlogAttr replaces the builtin keys of slog with lork field keys.
+func replaceSlogAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) != 0 {
+		return attr
+	}
+
+	switch attr.Key {
+	case slog.MessageKey:
+		attr.Key = lork.MessageFieldKey
+	case slog.SourceKey:
+		source, ok := attr.Value.Any().(*slog.Source)
+		if !ok {
+			return attr
+		}
+		if len(source.File) == 0 {
+			// no source found, remove it
+			return slog.Attr{}
+		}
+		// group without key will be inlined
+		return slog.Group("",
+			slog.String(lork.CallerFieldKey, source.File+":"+strconv.Itoa(source.Line)),
+			slog.String(lork.FunctionFieldKey, source.Function))
+	}
+
+	return attr
+}
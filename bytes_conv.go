@@ -15,7 +15,17 @@
 package lork
 
 import (
+	"bytes"
 	"errors"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/buger/jsonparser"
+)
+
+const (
+	hexDigits = "0123456789abcdef"
 )
 
 var (
@@ -60,3 +70,115 @@ func leadingInt(s []byte) (x int64, rem []byte, err error) {
 	}
 	return x, s[i:], nil
 }
+
+// appendJsonString appends s as quoted json string to dst.
+func appendJsonString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendEscapedString(dst, s)
+
+	return append(dst, '"')
+}
+
+// appendEscapedString appends s with json escaping to dst without quotes.
+func appendEscapedString(dst []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, s[start:i]...)
+				dst = append(dst, "\ufffd"...)
+				i += size
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+		if c >= 0x20 && c != '"' && c != '\\' {
+			i++
+			continue
+		}
+
+		dst = append(dst, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+		}
+		i++
+		start = i
+	}
+	return append(dst, s[start:]...)
+}
+
+// unescape unescapes the json escaped string value with scratch, and the value is
+// returned as is if it can't be unescaped.
+func unescape(v, scratch []byte) []byte {
+	unescaped, err := jsonparser.Unescape(v, scratch)
+	if err != nil {
+		return v
+	}
+
+	return unescaped
+}
+
+// writeUnescaped writes the json escaped string value into buf after unescaping it
+// with scratch.
+func writeUnescaped(buf *bytes.Buffer, v, scratch []byte) {
+	buf.Write(unescape(v, scratch))
+}
+
+// needsJsonEscape checks if the value contains characters to escape in json string.
+func needsJsonEscape(value []byte) bool {
+	ascii := true
+	for _, c := range value {
+		if c < 0x20 || c == '"' || c == '\\' {
+			return true
+		}
+		if c >= utf8.RuneSelf {
+			ascii = false
+		}
+	}
+
+	return !ascii && !utf8.Valid(value)
+}
+
+// appendJsonFloat appends float value as json value to dst, NaN and Inf will be
+// quoted as string.
+func appendJsonFloat(dst []byte, value float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(value):
+		return append(dst, `"NaN"`...)
+	case math.IsInf(value, 1):
+		return append(dst, `"+Inf"`...)
+	case math.IsInf(value, -1):
+		return append(dst, `"-Inf"`...)
+	default:
+		return strconv.AppendFloat(dst, value, 'f', -1, bitSize)
+	}
+}
+
+// isJsonObject checks if given json value is a non-empty json object.
+func isJsonObject(v []byte) bool {
+	v = bytes.TrimSpace(v)
+	if len(v) < 2 || v[0] != '{' || v[len(v)-1] != '}' {
+		return false
+	}
+
+	return len(bytes.TrimSpace(v[1:len(v)-1])) > 0
+}
+
+func isJsonArray(v []byte) bool {
+	v = bytes.TrimSpace(v)
+
+	return len(v) >= 2 && v[0] == '[' && v[len(v)-1] == ']'
+}
@@ -17,6 +17,7 @@ package lork
 type classicLogger struct {
 	name        string
 	multiWriter *MultiWriter
+	fields      *LogEvent
 }
 
 // NewClassicLogger create a classic ILogger. This logger is a builtin implementation.
@@ -34,6 +35,20 @@ func (l *classicLogger) Name() string {
 func (l *classicLogger) SetLevel(Level) {
 }
 
+func (l *classicLogger) SetCaller(bool, int) {
+}
+
+func (l *classicLogger) SetStackLevel(Level) {
+}
+
+func (l *classicLogger) With(fields func(Record)) ILogger {
+	return &classicLogger{
+		name:        l.name,
+		multiWriter: l.multiWriter,
+		fields:      captureFields(l.fields, fields),
+	}
+}
+
 func (l *classicLogger) Trace() Record {
 	return l.makeRecord(TraceLevel)
 }
@@ -74,5 +89,5 @@ func (l *classicLogger) Event(e *LogEvent) {
 }
 
 func (l *classicLogger) makeRecord(lvl Level) Record {
-	return newClassicRecord(lvl, l.multiWriter)
+	return newClassicRecord(lvl, l.multiWriter, l.fields)
 }
@@ -15,6 +15,7 @@
 package lork
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -29,19 +30,43 @@ var (
 )
 
 type classicRecord struct {
-	event    *LogEvent
-	recorder EventRecorder
+	level      Level
+	event      *LogEvent
+	recorder   EventRecorder
+	callerSkip int
+	withStack  bool
 }
 
-func newClassicRecord(lvl Level, recorder EventRecorder) Record {
+func newClassicRecord(lvl Level, recorder EventRecorder, fields *LogEvent) Record {
 	r := classicRecordPool.Get().(*classicRecord)
+	r.level = lvl
 	r.event = NewLogEvent()
 	r.recorder = recorder
+	r.callerSkip = -1
+	r.withStack = false
 	r.event.appendLevel(lvl)
+	if fields != nil {
+		r.event.appendFields(fields)
+	}
 
 	return r
 }
 
+// captureFields encodes the fields added in given function into a new LogEvent,
+// the fields of parent will be kept in front if parent is not nil.
+func captureFields(parent *LogEvent, fields func(Record)) *LogEvent {
+	r := &classicRecord{
+		event:      NewLogEvent(),
+		callerSkip: -1,
+	}
+	if parent != nil {
+		r.event.appendFields(parent)
+	}
+	fields(r)
+
+	return r.event
+}
+
 func (r *classicRecord) Str(key, val string) Record {
 	r.event.appendString(key, val)
 	return r
@@ -58,7 +83,7 @@ func (r *classicRecord) Bytes(key string, val []byte) Record {
 }
 
 func (r *classicRecord) Err(err error) Record {
-	r.event.appendString(ErrorFieldKey, err.Error())
+	EncodeError(r, err)
 	return r
 }
 
@@ -222,20 +247,76 @@ func (r *classicRecord) Any(key string, val interface{}) Record {
 	return r
 }
 
-func (r *classicRecord) Msge() {
-	if err := r.recorder.WriteEvent(r.event); err != nil {
-		Reportf("fail to write event: %v", err)
-	}
+func (r *classicRecord) Dict(key string, dict func(r Record)) Record {
+	r.event.appendDict(key, dict)
+	return r
+}
 
-	classicRecordPool.Put(r)
+func (r *classicRecord) Object(key string, obj ObjectMarshaler) Record {
+	r.event.appendObject(key, obj)
+	return r
+}
+
+func (r *classicRecord) Array(key string, arr ArrayMarshaler) Record {
+	r.event.appendArrayMarshaler(key, arr)
+	return r
+}
+
+func (r *classicRecord) Ctx(ctx context.Context) Record {
+	extractContext(r.recorder, ctx, r)
+	return r
+}
+
+func (r *classicRecord) Caller(skip int) Record {
+	r.callerSkip = skip
+	return r
+}
+
+func (r *classicRecord) Stack() Record {
+	r.withStack = true
+	return r
+}
+
+func (r *classicRecord) Msge() {
+	r.write()
 }
 
 func (r *classicRecord) Msg(msg string) {
 	r.event.appendMessage(msg)
-	r.Msge()
+	r.write()
 }
 
 func (r *classicRecord) Msgf(format string, v ...interface{}) {
 	r.event.appendMessage(fmt.Sprintf(format, v...))
-	r.Msge()
+	r.write()
+}
+
+// write writes the event with recorder, this must be called by Msg, Msgf and Msge
+// directly to keep the depth of caller.
+func (r *classicRecord) write() {
+	// skip write and Msg
+	skip := 2
+	if r.callerSkip > 0 {
+		skip += r.callerSkip
+	}
+	if r.callerSkip >= 0 && r.event.caller.Len() == 0 {
+		r.event.appendCaller(skip)
+	}
+	if r.withStack && r.event.stack.Len() == 0 {
+		r.event.appendStack(skip)
+	}
+
+	// keep the message before the event is recycled by recorder
+	lvl := r.level
+	var msg string
+	if lvl == FatalLevel || lvl == PanicLevel {
+		msg = string(r.event.Message())
+	}
+
+	if err := r.recorder.WriteEvent(r.event); err != nil {
+		Reportf("fail to write event: %v", err)
+	}
+
+	classicRecordPool.Put(r)
+	Terminate(lvl, msg)
 }
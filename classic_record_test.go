@@ -0,0 +1,343 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type eventCollector struct {
+	events []*LogEvent
+}
+
+func (w *eventCollector) Name() string {
+	return "COLLECTOR"
+}
+
+func (w *eventCollector) DoWrite(event *LogEvent) error {
+	w.events = append(w.events, event.Copy())
+	return nil
+}
+
+func (w *eventCollector) last() *LogEvent {
+	return w.events[len(w.events)-1]
+}
+
+func newCollectorContext() (*LoggerContext, *eventCollector) {
+	collector := &eventCollector{}
+	ctx := NewLoggerContext(NewClassicLogger)
+	ctx.RealLogger(RootLoggerName).AddWriter(collector)
+
+	return ctx, collector
+}
+
+func currentLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return file + ":" + strconv.Itoa(line+1)
+}
+
+func firstFrame(stack []byte) string {
+	frame, _, _, _ := jsonparser.Get(stack, "[0]")
+	file, _ := jsonparser.GetString(frame, "file")
+	line, _ := jsonparser.GetInt(frame, "line")
+	return file + ":" + strconv.FormatInt(line, 10)
+}
+
+func collectFields(e *LogEvent) string {
+	var fields string
+	_ = e.Fields(func(k, v []byte, _ bool) error {
+		fields += string(k) + "=" + string(v) + " "
+		return nil
+	})
+	return fields
+}
+
+func logWithWrapper(logger ILogger) {
+	logger.Info().Msg("from wrapper")
+}
+
+var _ = ginkgo.Describe("classic record", func() {
+	ginkgo.It("no caller by default", func() {
+		ctx, collector := newCollectorContext()
+		ctx.Logger("test").Info().Msg("hello")
+		Expect(collector.last().Caller()).To(BeEmpty())
+	})
+	ginkgo.It("caller of context", func() {
+		ctx, collector := newCollectorContext()
+		ctx.SetCaller(true, 0)
+		line := currentLine()
+		ctx.Logger("github.com/coolerfall/lork").Info().Msg("hello")
+		Expect(string(collector.last().Caller())).To(Equal(line))
+		Expect(string(collector.last().CallerFunc())).To(HavePrefix("github.com/coolerfall/lork."))
+
+		line = currentLine()
+		ctx.Logger("test").Warn().Msgf("hello %s", "lork")
+		Expect(string(collector.last().Caller())).To(Equal(line))
+	})
+	ginkgo.It("caller of logger with skip", func() {
+		ctx, collector := newCollectorContext()
+		logger := ctx.Logger("test")
+		logger.SetCaller(true, 1)
+		line := currentLine()
+		logWithWrapper(logger)
+		Expect(string(collector.last().Caller())).To(Equal(line))
+
+		ctx.Logger("other").Info().Msge()
+		Expect(collector.last().Caller()).To(BeEmpty())
+	})
+	ginkgo.It("explicit caller", func() {
+		ctx, collector := newCollectorContext()
+		line := currentLine()
+		ctx.Logger("test").Info().Caller(0).Msge()
+		Expect(string(collector.last().Caller())).To(Equal(line))
+	})
+	ginkgo.It("no stack by default", func() {
+		ctx, collector := newCollectorContext()
+		ctx.Logger("test").Error().Msg("hello")
+		Expect(collector.last().Stack()).To(BeEmpty())
+	})
+	ginkgo.It("stack at level", func() {
+		ctx, collector := newCollectorContext()
+		ctx.SetStackLevel(ErrorLevel)
+		logger := ctx.Logger("test")
+		logger.Warn().Msg("hello")
+		Expect(collector.last().Stack()).To(BeEmpty())
+
+		line := currentLine()
+		logger.Error().Msg("hello")
+		stack := collector.last().Stack()
+		Expect(firstFrame(stack)).To(Equal(line))
+		fn, _ := jsonparser.GetString(stack, "[0]", "func")
+		Expect(strings.HasPrefix(fn, "github.com/coolerfall/lork.")).To(BeTrue())
+	})
+	ginkgo.It("explicit stack with skip", func() {
+		ctx, collector := newCollectorContext()
+		line := currentLine()
+		ctx.Logger("test").Info().Stack().Msge()
+		Expect(firstFrame(collector.last().Stack())).To(Equal(line))
+
+		logger := ctx.Logger("test")
+		logger.SetCaller(true, 1)
+		logger.SetStackLevel(InfoLevel)
+		line = currentLine()
+		logWithWrapper(logger)
+		Expect(firstFrame(collector.last().Stack())).To(Equal(line))
+	})
+	ginkgo.It("omit stack if skip is too deep", func() {
+		Expect(StackTrace(1000)).To(BeEmpty())
+
+		ctx, collector := newCollectorContext()
+		logger := ctx.Logger("test")
+		logger.SetCaller(true, 1000)
+		logger.SetStackLevel(InfoLevel)
+		logger.Info().Msg("hello")
+		Expect(collector.last().Stack()).To(BeEmpty())
+
+		out, err := NewJsonEncoder().Encode(collector.last())
+		Expect(err).To(BeNil())
+		Expect(string(out)).NotTo(ContainSubstring(`"stack"`))
+	})
+	ginkgo.It("with bound fields", func() {
+		ctx, collector := newCollectorContext()
+		logger := ctx.Logger("test")
+		child := logger.With(func(r Record) {
+			r.Str("request_id", "abc").Int("tenant", 42)
+		})
+		grandchild := child.With(func(r Record) {
+			r.Bool("retry", true)
+		})
+
+		child.Info().Str("key", "value").Msg("hello")
+		Expect(string(collector.last().LoggerName())).To(Equal("test"))
+		Expect(collectFields(collector.last())).To(Equal(
+			"request_id=abc tenant=42 key=value "))
+
+		grandchild.Warn().Msge()
+		Expect(collectFields(collector.last())).To(Equal(
+			"request_id=abc tenant=42 retry=true "))
+
+		logger.Info().Msge()
+		Expect(collectFields(collector.last())).To(BeEmpty())
+
+		count := len(collector.events)
+		logger.SetLevel(WarnLevel)
+		child.Info().Msg("ignored")
+		Expect(collector.events).To(HaveLen(count))
+		grandchild.Level(ErrorLevel).Msg("written")
+		Expect(collector.events).To(HaveLen(count + 1))
+	})
+	ginkgo.It("with nested fields", func() {
+		ctx, collector := newCollectorContext()
+		logger := ctx.Logger("test")
+		logger.Info().Dict("user", func(r Record) {
+			r.Str("name", "lork\"").Int("age", 3).Dict("tags", func(r Record) {
+				r.Strs("list", []string{"a", "b"})
+			})
+		}).Object("point", ObjectMarshalerFunc(func(r Record) {
+			r.Int("x", 1).Int("y", 2)
+		})).Array("items", ArrayMarshalerFunc(func(a ArrayEncoder) {
+			a.Int(1).Str("two").Dict(func(r Record) {
+				r.Bool("three", true)
+			}).Array(ArrayMarshalerFunc(func(a ArrayEncoder) {}))
+		})).Dict("empty", func(r Record) {}).Msg("hello")
+
+		out, err := NewJsonEncoder().Encode(collector.last())
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"message":"hello",` +
+			`"user":{"name":"lork\"","age":3,"tags":{"list":["a","b"]}},` +
+			`"point":{"x":1,"y":2},"items":[1,"two",{"three":true},[]],"empty":{}}` + "\n"))
+	})
+	ginkgo.It("nested fields without allocation", func() {
+		if raceEnabled {
+			ginkgo.Skip("allocations are not stable with race detector")
+		}
+		point := ObjectMarshalerFunc(func(r Record) {
+			r.Int("x", 1).Str("y", "2")
+		})
+		allocs := testing.AllocsPerRun(100, func() {
+			e := NewLogEvent()
+			e.appendDict("dict", func(r Record) {
+				r.Str("k", "v").Object("point", point)
+			})
+			e.appendObject("point", point)
+			e.Recycle()
+		})
+		Expect(allocs).To(BeZero())
+	})
+	ginkgo.It("with lazy fields", func() {
+		ctx, collector := newCollectorContext()
+		logger := ctx.Logger("test")
+		logger.SetLevel(InfoLevel)
+		var count int
+		lazy := Lazy(func() interface{} {
+			count++
+			return count
+		})
+
+		logger.Debug().Any("lazy", lazy).Msg("ignored")
+		Expect(count).To(BeZero())
+
+		logger.Info().Any("lazy", lazy).Str("key", "value").Msg("hello")
+		Expect(collectFields(collector.last())).To(Equal("key=value lazy=1 "))
+		Expect(collectFields(collector.last())).To(Equal("key=value lazy=1 "))
+		Expect(count).To(Equal(1))
+
+		child := logger.With(func(r Record) {
+			r.Any("bound", lazy)
+		})
+		child.Info().Msge()
+		Expect(collectFields(collector.last())).To(Equal("bound=2 "))
+		child.Info().Dict("dict", func(r Record) {
+			r.Any("nested", Lazy(func() interface{} {
+				return Lazy(func() interface{} { return "deep" })
+			}))
+		}).Msge()
+		Expect(collectFields(collector.last())).To(Equal(`dict={"nested":"deep"} bound=3 `))
+	})
+	ginkgo.It("resolve lazy fields once before writing", func() {
+		var count int
+		event := NewLogEvent()
+		event.appendLevel(InfoLevel)
+		event.appendAny("lazy", Lazy(func() interface{} {
+			count++
+			return "value"
+		}))
+		Expect(collectFields(event)).To(BeEmpty())
+		Expect(count).To(BeZero())
+
+		first, second := &eventCollector{}, &eventCollector{}
+		mw := NewMultiWriter()
+		mw.AddWriter(first, second)
+		Expect(mw.WriteEvent(event)).To(BeNil())
+		Expect(count).To(Equal(1))
+		Expect(collectFields(first.last())).To(Equal("lazy=value "))
+		Expect(collectFields(second.last())).To(Equal("lazy=value "))
+	})
+	ginkgo.It("with error chain", func() {
+		ctx, collector := newCollectorContext()
+		logger := ctx.Logger("test")
+		err := fmt.Errorf("load: %w", &multiError{errs: []error{
+			&codeError{code: 404, msg: "not found"},
+			errors.New("timeout"),
+		}})
+
+		logger.Error().Err(err).Msge()
+		out, _ := NewJsonEncoder().Encode(collector.last())
+		Expect(string(out)).To(HaveSuffix(`"error":"load: not found; timeout",` +
+			`"error_type":"*fmt.wrapError","error_causes":[{"message":"not found; timeout",` +
+			`"type":"*lork.multiError","causes":[` +
+			`{"message":"not found","type":"*lork.codeError","details":{"code":404}},` +
+			`{"message":"timeout","type":"*errors.errorString"}]}]}` + "\n"))
+
+		logger.Error().Err(&codeError{code: 500, msg: "internal"}).Msge()
+		out, _ = NewJsonEncoder().Encode(collector.last())
+		Expect(string(out)).To(HaveSuffix(`"error":"internal","error_type":"*lork.codeError",` +
+			`"error_details":{"code":500}}` + "\n"))
+
+		logger.Error().Errs("errs", []error{err, nil}).Msge()
+		out, _ = NewJsonEncoder().Encode(collector.last())
+		Expect(string(out)).To(ContainSubstring(`"errs":[{"message":"load: not found; timeout",` +
+			`"type":"*fmt.wrapError","causes":[{"message":"not found; timeout"`))
+		Expect(string(out)).To(HaveSuffix(`"type":"*errors.errorString"}]}]},null]}` + "\n"))
+
+		pe := NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#message #error{causes}"
+		})
+		logger.Error().Err(err).Msg("failed")
+		out, _ = pe.Encode(collector.last())
+		Expect(string(out)).To(Equal("failed load: not found; timeout" +
+			"\n\tcaused by *lork.multiError: not found; timeout" +
+			"\n\t\tcaused by *lork.codeError: not found" +
+			"\n\t\tcaused by *errors.errorString: timeout\n"))
+	})
+})
+
+type codeError struct {
+	code int
+	msg  string
+}
+
+func (e *codeError) Error() string {
+	return e.msg
+}
+
+func (e *codeError) MarshalLogError(r Record) {
+	r.Int("code", e.code)
+}
+
+type multiError struct {
+	errs []error
+}
+
+func (e *multiError) Error() string {
+	var msgs []string
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *multiError) Unwrap() []error {
+	return e.errs
+}
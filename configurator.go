@@ -14,6 +14,10 @@
 
 package lork
 
+import (
+	"sync"
+)
+
 const (
 	StatusNeutral ExecutionStatus = iota
 	StatusNext
@@ -31,31 +35,132 @@ type Configurator interface {
 type ManualConfigurator struct {
 	isConfigured bool
 	writers      []Writer
+	hooks        []Hook
+	extractors   []ContextExtractor
+	loggers      []loggerConfig
 	context      *LoggerContext
 }
 
-var manual = &ManualConfigurator{}
+// LoggerOption represents available options for named logger.
+type LoggerOption struct {
+	Writers  []Writer
+	Additive bool
+}
+
+type loggerConfig struct {
+	name string
+	opts *LoggerOption
+}
+
+var (
+	manual = &ManualConfigurator{}
+
+	configuratorLocker sync.Mutex
+	extraConfigurators []Configurator
+)
+
+// AddConfigurator adds configurators which will be executed in order before
+// ManualConfigurator when the provider is prepared, e.g. FileConfigurator.
+func AddConfigurator(c ...Configurator) {
+	configuratorLocker.Lock()
+	defer configuratorLocker.Unlock()
+
+	extraConfigurators = append(extraConfigurators, c...)
+}
+
+// addedConfigurators returns the configurators added with AddConfigurator.
+func addedConfigurators() []Configurator {
+	configuratorLocker.Lock()
+	defer configuratorLocker.Unlock()
+
+	return append([]Configurator(nil), extraConfigurators...)
+}
 
 // Manual gets ManualConfigurator to use.
 func Manual() *ManualConfigurator {
 	return manual
 }
 
+// Configure adds the writers into root logger. The hooks and loggers are not installed
+// here, they are installed when the provider is prepared even if other configurator
+// stops the chain.
 func (c *ManualConfigurator) Configure(ctx *LoggerContext) ExecutionStatus {
 	if len(c.writers) == 0 {
 		return StatusNext
 	}
 
 	ctx.RealLogger(RootLoggerName).AddWriter(c.writers...)
-	c.context = ctx
 
 	return StatusNoNext
 }
 
+// attach installs the hooks, context extractors and loggers into context once, and the
+// ones added later will be installed immediately.
+func (c *ManualConfigurator) attach(ctx *LoggerContext) {
+	if c.context == ctx {
+		return
+	}
+
+	if len(c.hooks) > 0 {
+		ctx.AddHook(c.hooks...)
+	}
+	if len(c.extractors) > 0 {
+		ctx.RegisterContextExtractor(c.extractors...)
+	}
+	for _, lc := range c.loggers {
+		lc.apply(ctx)
+	}
+	c.context = ctx
+}
+
+// ConfigLogger configures the logger with given name, the writers will be attached
+// to the named logger once configured. The events of named logger will also be
+// written into the writers of ancestors unless Additive is false.
+func (c *ManualConfigurator) ConfigLogger(name string, options ...func(*LoggerOption)) {
+	opts := &LoggerOption{
+		Additive: true,
+	}
+
+	for _, f := range options {
+		f(opts)
+	}
+
+	lc := loggerConfig{name: name, opts: opts}
+	c.loggers = append(c.loggers, lc)
+	if c.context != nil {
+		lc.apply(c.context)
+	}
+}
+
 func (c *ManualConfigurator) AddWriter(writers ...Writer) {
 	c.writers = append(c.writers, writers...)
 }
 
+// AddHook adds global hooks, the hooks will be added into context once configured.
+func (c *ManualConfigurator) AddHook(hooks ...Hook) {
+	c.hooks = append(c.hooks, hooks...)
+	if c.context != nil {
+		c.context.AddHook(hooks...)
+	}
+}
+
+// addExtractor adds context extractors, they will be registered into context once
+// configured.
+func (c *ManualConfigurator) addExtractor(extractors ...ContextExtractor) {
+	c.extractors = append(c.extractors, extractors...)
+	if c.context != nil {
+		c.context.RegisterContextExtractor(extractors...)
+	}
+}
+
+// resetExtractor removes all the context extractors.
+func (c *ManualConfigurator) resetExtractor() {
+	c.extractors = nil
+	if c.context != nil {
+		c.context.ResetContextExtractor()
+	}
+}
+
 func (c *ManualConfigurator) GetWriter(name string) Writer {
 	for _, w := range c.writers {
 		if w.Name() == name {
@@ -76,13 +181,21 @@ func (c *ManualConfigurator) Attached(writer Writer) bool {
 	return false
 }
 
+// ResetWriter removes and stops the writers of root logger and named loggers.
 func (c *ManualConfigurator) ResetWriter() {
 	c.writers = c.writers[:0]
+	c.loggers = c.loggers[:0]
 	if c.context != nil {
-		c.context.RealLogger(RootLoggerName).ResetWriter()
+		c.context.ResetWriter()
 	}
 }
 
+func (lc loggerConfig) apply(ctx *LoggerContext) {
+	logger := ctx.RealLogger(lc.name)
+	logger.AddWriter(lc.opts.Writers...)
+	logger.SetAdditive(lc.opts.Additive)
+}
+
 type basicConfigurator struct {
 }
 
@@ -15,6 +15,7 @@
 package lork
 
 import (
+	"fmt"
 	"os"
 	"sync"
 )
@@ -49,6 +50,38 @@ func NewConsoleWriter(options ...func(*ConsoleWriterOption)) Writer {
 	return NewBytesWriter(cw)
 }
 
+func init() {
+	RegisterWriter("console", newConsoleWriterFromConfig)
+}
+
+type consoleWriterConfig struct {
+	Encoder ComponentConfig `json:"encoder"`
+	Filter  ComponentConfig `json:"filter"`
+}
+
+func newConsoleWriterFromConfig(d OptionDecoder) (Writer, error) {
+	var c consoleWriterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	encoder, err := d.Encoder(c.Encoder)
+	if err != nil {
+		return nil, fmt.Errorf("encoder: %w", err)
+	}
+	filter, err := d.Filter(c.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+
+	return NewConsoleWriter(func(o *ConsoleWriterOption) {
+		o.Name = d.Name()
+		if encoder != nil {
+			o.Encoder = encoder
+		}
+		o.Filter = filter
+	}), nil
+}
+
 func (w *consoleWriter) Write(p []byte) (n int, err error) {
 	w.locker.Lock()
 	defer w.locker.Unlock()
@@ -15,6 +15,9 @@
 package lork
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -24,6 +27,8 @@ type LoggerContext struct {
 
 	rootLogger  *namedLogger
 	loggerCache map[string]*namedLogger
+	hooks       *hookChain
+	extractors  *contextExtractors
 }
 
 type NewLogger func(name string, writer *MultiWriter) ILogger
@@ -31,11 +36,16 @@ type NewLogger func(name string, writer *MultiWriter) ILogger
 // NewLoggerContext creates a new instance of LoggerContext.
 func NewLoggerContext(newLogger NewLogger) *LoggerContext {
 	writer := NewMultiWriter()
+	writer.hooks = newHookChain()
+	writer.routes = newWriterRoutes(writer)
+	writer.extractors = newContextExtractors()
 	realLogger := newLogger(RootLoggerName, writer)
 	rootLogger := newNamedLogger(RootLoggerName, realLogger, writer)
 	ctx := &LoggerContext{
 		rootLogger:  rootLogger,
 		loggerCache: make(map[string]*namedLogger),
+		hooks:       writer.hooks,
+		extractors:  writer.extractors,
 	}
 
 	return ctx
@@ -79,6 +89,106 @@ func (c *LoggerContext) RealLogger(name string) *namedLogger {
 	}
 }
 
+// findLogger finds the logger with given name without creating it.
+func (c *LoggerContext) findLogger(name string) (*namedLogger, bool) {
+	c.loggerLocker.Lock()
+	defer c.loggerLocker.Unlock()
+
+	if strings.EqualFold(RootLoggerName, name) {
+		return c.rootLogger, true
+	}
+	logger, ok := c.loggerCache[name]
+
+	return logger, ok
+}
+
+// loggers returns root logger and all the named loggers sorted by name.
+func (c *LoggerContext) loggers() []*namedLogger {
+	c.loggerLocker.Lock()
+	loggers := make([]*namedLogger, 0, len(c.loggerCache)+1)
+	for _, logger := range c.loggerCache {
+		loggers = append(loggers, logger)
+	}
+	c.loggerLocker.Unlock()
+
+	sort.Slice(loggers, func(i, j int) bool {
+		return loggers[i].name < loggers[j].name
+	})
+
+	return append([]*namedLogger{c.rootLogger}, loggers...)
+}
+
+// SetCaller enables or disables caller capturing for all loggers in this context.
+func (c *LoggerContext) SetCaller(enabled bool, skip int) {
+	c.rootLogger.SetCaller(enabled, skip)
+}
+
+// SetStackLevel sets the level at or above which stack trace will be captured for
+// all loggers in this context.
+func (c *LoggerContext) SetStackLevel(lvl Level) {
+	c.rootLogger.SetStackLevel(lvl)
+}
+
+// AddHook adds global hooks which will be fired for events of all loggers in this
+// context, before the hooks of named loggers.
+func (c *LoggerContext) AddHook(hooks ...Hook) {
+	c.hooks.add("", hooks...)
+}
+
+// RegisterContextExtractor registers extractors which will be used in Record.Ctx of
+// loggers in this context. The extractors will be called in the order they were
+// registered.
+func (c *LoggerContext) RegisterContextExtractor(extractor ...ContextExtractor) {
+	c.extractors.register(extractor...)
+}
+
+// ResetContextExtractor removes all the context extractors in this context.
+func (c *LoggerContext) ResetContextExtractor() {
+	c.extractors.reset()
+}
+
+// Flush blocks until all the writers in this context are flushed or the context is done.
+func (c *LoggerContext) Flush(ctx context.Context) error {
+	return c.rootLogger.multiWriter.Flush(ctx)
+}
+
+// Shutdown flushes all the writers in this context, and then stops them. Writers
+// wrapping others are stopped before the wrapped ones, so queued events are drained
+// before files and sockets are closed. The returned error reports what could not
+// be flushed or stopped before the context is done.
+func (c *LoggerContext) Shutdown(ctx context.Context) error {
+	// write summary of sampling before flushing
+	c.hooks.stopSamplers()
+	err := c.Flush(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		c.resetWriter(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		if err == nil {
+			err = fmt.Errorf("stop writers: %w", ctx.Err())
+		}
+		return err
+	}
+}
+
+// ResetWriter removes and stops the writers of root logger and all named loggers.
+func (c *LoggerContext) ResetWriter() {
+	c.resetWriter(context.Background())
+}
+
+// resetWriter removes and stops all the writers in this context, until ctx is done.
+func (c *LoggerContext) resetWriter(ctx context.Context) {
+	c.rootLogger.multiWriter.resetWriter(ctx)
+	c.rootLogger.multiWriter.routes.resetAll(ctx)
+}
+
 // Logger is implementation for ILoggerFactory.
 func (c *LoggerContext) Logger(name string) ILogger {
 	return c.RealLogger(name)
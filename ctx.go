@@ -0,0 +1,137 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type ctxLoggerKey struct{}
+
+// ContextExtractor extracts values from context and adds them into record as fields.
+type ContextExtractor func(ctx context.Context, r Record)
+
+// WithContext returns a copy of ctx with the given logger stored.
+func WithContext(ctx context.Context, logger ILogger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, logger)
+}
+
+// Ctx gets the logger stored in ctx with WithContext, the global root logger will
+// be returned if no logger stored.
+func Ctx(ctx context.Context) ILogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxLoggerKey{}).(ILogger); ok {
+			return logger
+		}
+	}
+
+	return Logger()
+}
+
+// RegisterContextExtractor registers extractors which will be used in Record.Ctx of
+// the loggers of bound provider. The extractors will be called in the order they were
+// registered, and they are installed into the context when the provider is prepared.
+func RegisterContextExtractor(extractor ...ContextExtractor) {
+	manual.addExtractor(extractor...)
+}
+
+// ResetContextExtractor removes all the context extractors registered with
+// RegisterContextExtractor.
+func ResetContextExtractor() {
+	manual.resetExtractor()
+}
+
+// ExtractContext adds values in ctx into record with the extractors of bound provider.
+// This is used to implement Record.Ctx for providers.
+func ExtractContext(ctx context.Context, r Record) {
+	if ctx == nil {
+		return
+	}
+
+	if lc, ok := getLoggerFactory().(*LoggerContext); ok {
+		lc.extractors.extract(ctx, r)
+	}
+}
+
+// extractContext adds values in ctx into record with the extractors of the context
+// which recorder belongs to, or the ones of bound provider if not found.
+func extractContext(recorder EventRecorder, ctx context.Context, r Record) {
+	if mw, ok := recorder.(*MultiWriter); ok && mw.extractors != nil {
+		mw.extractors.extract(ctx, r)
+		return
+	}
+
+	ExtractContext(ctx, r)
+}
+
+// contextExtractors holds the context extractors of a LoggerContext.
+type contextExtractors struct {
+	locker     sync.Mutex
+	extractors atomic.Value
+}
+
+func newContextExtractors() *contextExtractors {
+	ce := &contextExtractors{}
+	ce.extractors.Store([]ContextExtractor(nil))
+
+	return ce
+}
+
+func (ce *contextExtractors) register(extractor ...ContextExtractor) {
+	ce.locker.Lock()
+	defer ce.locker.Unlock()
+
+	old := ce.extractors.Load().([]ContextExtractor)
+	registered := make([]ContextExtractor, 0, len(old)+len(extractor))
+	registered = append(registered, old...)
+	registered = append(registered, extractor...)
+	ce.extractors.Store(registered)
+}
+
+func (ce *contextExtractors) reset() {
+	ce.locker.Lock()
+	defer ce.locker.Unlock()
+
+	ce.extractors.Store([]ContextExtractor(nil))
+}
+
+func (ce *contextExtractors) extract(ctx context.Context, r Record) {
+	if ctx == nil {
+		return
+	}
+
+	for _, extract := range ce.extractors.Load().([]ContextExtractor) {
+		extract(ctx, r)
+	}
+}
+
+// NewContextValueExtractor creates a ContextExtractor which adds the value of key
+// in context as field with given name. Nothing will be added if value not found.
+func NewContextValueExtractor(key interface{}, field string) ContextExtractor {
+	return func(ctx context.Context, r Record) {
+		switch val := ctx.Value(key).(type) {
+		case nil:
+		case string:
+			r.Str(field, val)
+		case fmt.Stringer:
+			r.Str(field, val.String())
+		default:
+			r.Any(field, val)
+		}
+	}
+}
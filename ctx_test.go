@@ -0,0 +1,77 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ctxKey string
+
+var _ = ginkgo.Describe("context", func() {
+	ginkgo.It("logger in context", func() {
+		ctx, _ := newCollectorContext()
+		logger := ctx.Logger("test")
+		c := WithContext(context.Background(), logger)
+		Expect(Ctx(c)).To(BeIdenticalTo(logger))
+		Expect(Ctx(context.Background())).NotTo(BeNil())
+	})
+	ginkgo.It("extract values", func() {
+		ctx, collector := newCollectorContext()
+		ctx.RegisterContextExtractor(NewContextValueExtractor(ctxKey("rid"), "request_id"))
+		ctx.RegisterContextExtractor(func(ctx context.Context, r Record) {
+			if uid, ok := ctx.Value(ctxKey("uid")).(int); ok {
+				r.Int("user_id", uid)
+			}
+		})
+
+		c := context.WithValue(context.Background(), ctxKey("rid"), "abc")
+		c = context.WithValue(c, ctxKey("uid"), 42)
+		ctx.Logger("test").Info().Ctx(c).Msg("hello")
+		Expect(collectFields(collector.last())).To(Equal("request_id=abc user_id=42 "))
+
+		ctx.Logger("test").Info().Ctx(context.Background()).Msg("hello")
+		Expect(collectFields(collector.last())).To(BeEmpty())
+
+		// extractors are not shared by other contexts
+		other, otherCollector := newCollectorContext()
+		other.Logger("test").Info().Ctx(c).Msg("hello")
+		Expect(collectFields(otherCollector.last())).To(BeEmpty())
+
+		ctx.ResetContextExtractor()
+		ctx.Logger("test").Info().Ctx(c).Msg("hello")
+		Expect(collectFields(collector.last())).To(BeEmpty())
+	})
+	ginkgo.It("install extractors of manual configurator", func() {
+		ctx, collector := newCollectorContext()
+		c := &ManualConfigurator{}
+		c.addExtractor(NewContextValueExtractor(ctxKey("rid"), "request_id"))
+		c.attach(ctx)
+		c.attach(ctx)
+		c.addExtractor(NewContextValueExtractor(ctxKey("uid"), "user_id"))
+
+		v := context.WithValue(context.Background(), ctxKey("rid"), "abc")
+		v = context.WithValue(v, ctxKey("uid"), 42)
+		ctx.Logger("test").Info().Ctx(v).Msg("hello")
+		Expect(collectFields(collector.last())).To(Equal("request_id=abc user_id=42 "))
+
+		c.resetExtractor()
+		ctx.Logger("test").Info().Ctx(v).Msg("hello")
+		Expect(collectFields(collector.last())).To(BeEmpty())
+	})
+})
@@ -0,0 +1,429 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/buger/jsonparser"
+)
+
+// EcsVersion is the version of Elastic Common Schema used by ecs encoder.
+const EcsVersion = "1.6.0"
+
+// ecsFieldKeys maps the error fields of lork to ECS fields. The error.details and
+// error.causes are custom fields which are not defined by ECS.
+var ecsFieldKeys = map[string]string{
+	ErrorFieldKey:        "error.message",
+	ErrorTypeFieldKey:    "error.type",
+	ErrorDetailsFieldKey: "error.details",
+	ErrorCausesFieldKey:  "error.causes",
+}
+
+// ecsReservedKeys are the keys written as dotted keys at top level, user fields
+// conflicting with them will be moved under labels as flat keys.
+var ecsReservedKeys = []string{"@timestamp", "log.level", "log.logger", "message",
+	"ecs.version"}
+
+// ecsField is a leaf field with dotted key, the key is stored in the keys buffer
+// of encoder.
+type ecsField struct {
+	start, end int
+	value      []byte
+	isString   bool
+}
+
+// ecsFields sorts the fields by dotted key, so the fields with same prefix are
+// adjacent and can be written in the same nested object.
+type ecsFields struct {
+	keys   []byte
+	fields []ecsField
+}
+
+func (f *ecsFields) Len() int {
+	return len(f.fields)
+}
+
+func (f *ecsFields) Less(i, j int) bool {
+	return bytes.Compare(f.key(i), f.key(j)) < 0
+}
+
+func (f *ecsFields) Swap(i, j int) {
+	f.fields[i], f.fields[j] = f.fields[j], f.fields[i]
+}
+
+func (f *ecsFields) key(i int) []byte {
+	return f.keys[f.fields[i].start:f.fields[i].end]
+}
+
+func (f *ecsFields) add(key, value []byte, isString bool) {
+	start := len(f.keys)
+	f.keys = append(f.keys, key...)
+	f.fields = append(f.fields, ecsField{
+		start:    start,
+		end:      len(f.keys),
+		value:    value,
+		isString: isString,
+	})
+}
+
+// addLabel adds a field under labels, which is a flat map of keyword values, so the
+// dots in key are replaced with underscores and the value is written as string.
+func (f *ecsFields) addLabel(key, value []byte) {
+	start := len(f.keys)
+	f.keys = append(f.keys, "labels."...)
+	for _, c := range key {
+		if c == '.' {
+			c = '_'
+		}
+		f.keys = append(f.keys, c)
+	}
+	f.fields = append(f.fields, ecsField{
+		start:    start,
+		end:      len(f.keys),
+		value:    value,
+		isString: true,
+	})
+}
+
+func (f *ecsFields) reset() {
+	f.keys = f.keys[:0]
+	f.fields = f.fields[:0]
+}
+
+// ecsEncoder encodes logging event into json format of Elastic Common Schema.
+type ecsEncoder struct {
+	locker sync.Mutex
+	buf    []byte
+	path   []byte
+	open   []byte
+	stack  []byte
+	fields ecsFields
+}
+
+// NewEcsEncoder creates a new instance of encoder to encode data to json which is
+// compliant with Elastic Common Schema. The @timestamp, log.level, log.logger,
+// message and ecs.version are written as dotted keys, and the other fields are
+// expanded into nested objects by dots, e.g. http.request.method will be written
+// as {"http":{"request":{"method":...}}}. The error fields are mapped to error.*,
+// and the stack trace is written as error.stack_trace.
+func NewEcsEncoder() Encoder {
+	return &ecsEncoder{}
+}
+
+func init() {
+	RegisterEncoder("ecs", func(d OptionDecoder) (Encoder, error) {
+		if err := d.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return NewEcsEncoder(), nil
+	})
+}
+
+func (ee *ecsEncoder) Encode(e *LogEvent) ([]byte, error) {
+	ee.locker.Lock()
+	defer ee.locker.Unlock()
+
+	var err error
+	ee.buf = append(ee.buf[:0], `{"@timestamp":"`...)
+	ee.buf, err = appendFormatUnix(ee.buf, e.Timestamp(), TimeFormatRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	ee.buf = append(ee.buf, `","log.level":"`...)
+	for _, c := range e.Level() {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		ee.buf = append(ee.buf, c)
+	}
+	ee.buf = append(ee.buf, '"', ',')
+	if logger := e.LoggerName(); len(logger) != 0 {
+		ee.writeKeyAndRaw("log.logger", logger)
+	}
+	ee.writeKeyAndRaw("message", e.Message())
+	ee.writeKeyAndValue("ecs.version", []byte(EcsVersion), true)
+
+	ee.collectFields(e)
+	ee.writeNested()
+
+	ee.buf[len(ee.buf)-1] = '}'
+	ee.buf = append(ee.buf, '\n')
+
+	return ee.buf, nil
+}
+
+// collectFields collects caller, stack and fields of event as leaf fields with dotted key.
+func (ee *ecsEncoder) collectFields(e *LogEvent) {
+	ee.fields.reset()
+	if caller := e.Caller(); len(caller) != 0 {
+		if i := bytes.LastIndexByte(caller, ':'); i > 0 {
+			ee.fields.add([]byte("log.origin.file.name"), caller[:i], true)
+			ee.fields.add([]byte("log.origin.file.line"), caller[i+1:], false)
+		} else {
+			ee.fields.add([]byte("log.origin.file.name"), caller, true)
+		}
+		if fn := e.CallerFunc(); len(fn) != 0 {
+			ee.fields.add([]byte("log.origin.function"), fn, true)
+		}
+	}
+
+	_ = e.Fields(func(k, v []byte, isString bool) error {
+		if key, ok := ecsFieldKeys[string(k)]; ok {
+			ee.path = append(ee.path[:0], key...)
+		} else {
+			ee.path = append(ee.path[:0], k...)
+		}
+		ee.walkField(v, isString)
+		return nil
+	})
+
+	if stack := e.Stack(); len(stack) != 0 {
+		ee.stack = appendStackText(ee.stack[:0], stack)
+		ee.fields.add([]byte("error.stack_trace"), ee.stack, true)
+	}
+
+	sort.Stable(&ee.fields)
+	for ee.renameConflicts() {
+		sort.Stable(&ee.fields)
+	}
+}
+
+// renameConflicts renames the leaf fields whose key is also the prefix of other
+// keys, e.g. user and user.id, to user.value. The fields must be sorted, and true
+// will be returned if any field was renamed.
+func (ee *ecsEncoder) renameConflicts() bool {
+	renamed := false
+	for i := range ee.fields.fields {
+		key := ee.fields.key(i)
+		// keys with the same prefix are adjacent after sorting
+		for j := i + 1; j < len(ee.fields.fields); j++ {
+			other := ee.fields.key(j)
+			if !bytes.HasPrefix(other, key) {
+				break
+			}
+			if len(other) > len(key) && other[len(key)] == '.' {
+				start := len(ee.fields.keys)
+				ee.fields.keys = append(ee.fields.keys, key...)
+				ee.fields.keys = append(ee.fields.keys, ".value"...)
+				ee.fields.fields[i].start = start
+				ee.fields.fields[i].end = len(ee.fields.keys)
+				renamed = true
+				break
+			}
+		}
+	}
+
+	return renamed
+}
+
+// walkField adds the field with current path, members of object are flattened.
+func (ee *ecsEncoder) walkField(v []byte, isString bool) {
+	if isString || !isJsonObject(v) {
+		if isEcsReserved(ee.path) {
+			ee.walkLabel(v, isString)
+		} else {
+			ee.fields.add(ee.path, v, isString)
+		}
+		return
+	}
+
+	n := len(ee.path)
+	defer func() {
+		ee.path = ee.path[:n]
+	}()
+
+	_ = jsonparser.ObjectEach(v, func(k []byte, mv []byte,
+		dataType jsonparser.ValueType, _ int) error {
+		ee.path = append(append(ee.path[:n], '.'), k...)
+		ee.walkField(mv, dataType == jsonparser.String)
+		return nil
+	})
+}
+
+// walkLabel adds the field with current path under labels, members of object and
+// elements of array are flattened since labels can only contain keyword values.
+func (ee *ecsEncoder) walkLabel(v []byte, isString bool) {
+	if isString || (!isJsonObject(v) && !isJsonArray(v)) {
+		ee.fields.addLabel(ee.path, v)
+		return
+	}
+
+	n := len(ee.path)
+	defer func() {
+		ee.path = ee.path[:n]
+	}()
+
+	if isJsonObject(v) {
+		_ = jsonparser.ObjectEach(v, func(k []byte, mv []byte,
+			dataType jsonparser.ValueType, _ int) error {
+			ee.path = append(append(ee.path[:n], '.'), k...)
+			ee.walkLabel(mv, dataType == jsonparser.String)
+			return nil
+		})
+		return
+	}
+
+	var index int64
+	_, _ = jsonparser.ArrayEach(v, func(av []byte, dataType jsonparser.ValueType,
+		_ int, _ error) {
+		ee.path = strconv.AppendInt(append(ee.path[:n], '.'), index, 10)
+		index++
+		ee.walkLabel(av, dataType == jsonparser.String)
+	})
+	if index == 0 {
+		ee.fields.addLabel(ee.path, emptyJsonArray)
+	}
+}
+
+// writeNested writes the sorted leaf fields, the fields with same prefix will be
+// written in the same nested object. Only the last one of the fields with same key
+// will be written.
+func (ee *ecsEncoder) writeNested() {
+	ee.open = ee.open[:0]
+	depth := 0
+	for i := range ee.fields.fields {
+		key := ee.fields.key(i)
+		if i+1 < len(ee.fields.fields) && bytes.Equal(key, ee.fields.key(i+1)) {
+			continue
+		}
+		parent, leaf := splitLastDot(key)
+
+		// close the objects not shared with parent of current key
+		common, commonLen := commonSegments(ee.open, parent)
+		for ; depth > common; depth-- {
+			ee.buf[len(ee.buf)-1] = '}'
+			ee.buf = append(ee.buf, ',')
+		}
+		ee.open = ee.open[:commonLen]
+
+		// open the objects of remaining segments
+		rest := parent[commonLen:]
+		for len(rest) > 0 {
+			if rest[0] == '.' {
+				rest = rest[1:]
+			}
+			seg := rest
+			if j := bytes.IndexByte(rest, '.'); j >= 0 {
+				seg = rest[:j]
+			}
+			rest = rest[len(seg):]
+			if len(ee.open) > 0 {
+				ee.open = append(ee.open, '.')
+			}
+			ee.open = append(ee.open, seg...)
+			ee.buf = append(ee.buf, '"')
+			ee.buf = append(ee.buf, seg...)
+			ee.buf = append(ee.buf, '"', ':', '{')
+			depth++
+		}
+
+		f := ee.fields.fields[i]
+		ee.buf = append(ee.buf, '"')
+		ee.buf = append(ee.buf, leaf...)
+		ee.buf = append(ee.buf, '"', ':')
+		ee.writeValue(f.value, f.isString)
+	}
+	for ; depth > 0; depth-- {
+		ee.buf[len(ee.buf)-1] = '}'
+		ee.buf = append(ee.buf, ',')
+	}
+}
+
+func (ee *ecsEncoder) writeKeyAndValue(key string, value []byte, isString bool) {
+	ee.buf = append(ee.buf, '"')
+	ee.buf = append(ee.buf, key...)
+	ee.buf = append(ee.buf, '"', ':')
+	ee.writeValue(value, isString)
+}
+
+// writeKeyAndRaw writes unescaped value of event as json string.
+func (ee *ecsEncoder) writeKeyAndRaw(key string, value []byte) {
+	ee.buf = append(ee.buf, '"')
+	ee.buf = append(ee.buf, key...)
+	ee.buf = append(ee.buf, '"', ':', '"')
+	if needsJsonEscape(value) {
+		ee.buf = appendEscapedString(ee.buf, string(value))
+	} else {
+		ee.buf = append(ee.buf, value...)
+	}
+	ee.buf = append(ee.buf, '"', ',')
+}
+
+func (ee *ecsEncoder) writeValue(value []byte, isString bool) {
+	if isString {
+		ee.buf = append(ee.buf, '"')
+	}
+	ee.buf = append(ee.buf, value...)
+	if isString {
+		ee.buf = append(ee.buf, '"')
+	}
+	ee.buf = append(ee.buf, ',')
+}
+
+// isEcsReserved checks if the key conflicts with the reserved keys, which means
+// the key is the same as, or the prefix of, or prefixed by a reserved key.
+func isEcsReserved(key []byte) bool {
+	for _, reserved := range ecsReservedKeys {
+		n := len(key)
+		if len(reserved) < n {
+			n = len(reserved)
+		}
+		if string(key[:n]) != reserved[:n] {
+			continue
+		}
+		if len(key) == len(reserved) || (len(key) > n && key[n] == '.') ||
+			(len(reserved) > n && reserved[n] == '.') {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitLastDot splits key into parent path and leaf by the last dot.
+func splitLastDot(key []byte) ([]byte, []byte) {
+	i := bytes.LastIndexByte(key, '.')
+	if i < 0 {
+		return nil, key
+	}
+
+	return key[:i], key[i+1:]
+}
+
+// commonSegments returns the number of common leading segments of two dotted paths,
+// and the length of the common part in a.
+func commonSegments(a, b []byte) (int, int) {
+	var count, length int
+	for i := 0; ; i++ {
+		aEnd, bEnd := i == len(a), i == len(b)
+		if aEnd || bEnd || a[i] == '.' || b[i] == '.' {
+			if (aEnd || a[i] == '.') && (bEnd || b[i] == '.') && i > length {
+				count++
+				length = i
+			}
+			if aEnd || bEnd || a[i] != b[i] {
+				return count, length
+			}
+			continue
+		}
+		if a[i] != b[i] {
+			return count, length
+		}
+	}
+}
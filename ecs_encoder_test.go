@@ -0,0 +1,76 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"encoding/json"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("ecs encoder", func() {
+	event := MakeEvent([]byte(`{"level":"ERROR","time":"2019-12-27T10:40:14.465199844+08:00",` +
+		`"logger_name":"github.com/lork","caller":"/go/src/app/main.go:42","func":"main.main",` +
+		`"message":"hello \"lork\"","http.request.method":"GET","service.name":"app",` +
+		`"http.response.status_code":200,"user":{"name":"lork","tags":["a","b"]},` +
+		`"user.id":1,"error":"read failed","error_type":"*errors.errorString",` +
+		`"stack":[{"func":"main.work","file":"/go/src/app/main.go","line":12}]}`))
+	var data []byte
+	rt, _ := appendFormatUnix(data, event.Timestamp(), TimeFormatRFC3339)
+
+	ginkgo.It("encode", func() {
+		out, err := NewEcsEncoder().Encode(event)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"@timestamp":"` + string(rt) + `","log.level":"error",` +
+			`"log.logger":"github.com/lork","message":"hello \"lork\"","ecs.version":"1.6.0",` +
+			`"error":{"message":"read failed","stack_trace":"main.work\n\t/go/src/app/main.go:12\n",` +
+			`"type":"*errors.errorString"},` +
+			`"http":{"request":{"method":"GET"},"response":{"status_code":200}},` +
+			`"log":{"origin":{"file":{"line":42,"name":"/go/src/app/main.go"},"function":"main.main"}},` +
+			`"service":{"name":"app"},"user":{"id":1,"name":"lork","tags":["a","b"]}}` + "\n"))
+		Expect(json.Valid(out)).To(BeTrue())
+	})
+	ginkgo.It("resolve conflicting keys", func() {
+		e := MakeEvent([]byte(`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00",` +
+			`"message":"hello","user":"bob","user.id":1,"@timestamp":"now","log.level":"debug",` +
+			`"ecs.version":"9","log":{"logger":"x"},"dup":1,"dup":2}`))
+		e.appendString("message", "user message")
+		out, err := NewEcsEncoder().Encode(e)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"@timestamp":"` + string(rt) + `","log.level":"info",` +
+			`"message":"hello","ecs.version":"1.6.0","dup":2,` +
+			`"labels":{"@timestamp":"now","ecs_version":"9","log_level":"debug",` +
+			`"log_logger":"x","message":"user message"},"user":{"id":1,"value":"bob"}}` + "\n"))
+		Expect(json.Valid(out)).To(BeTrue())
+	})
+	ginkgo.It("flatten labels", func() {
+		e := MakeEvent([]byte(`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00",` +
+			`"message":"hello","message.x":{"a":1,"b":[true,{"c":null}]},"log.level":[]}`))
+		out, err := NewEcsEncoder().Encode(e)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"@timestamp":"` + string(rt) + `","log.level":"info",` +
+			`"message":"hello","ecs.version":"1.6.0","labels":{"log_level":"[]",` +
+			`"message_x_a":"1","message_x_b_0":"true","message_x_b_1_c":"null"}}` + "\n"))
+		Expect(json.Valid(out)).To(BeTrue())
+	})
+	ginkgo.It("encode without fields", func() {
+		out, err := NewEcsEncoder().Encode(MakeEvent([]byte(
+			`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00","message":"hello"}`)))
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"@timestamp":"` + string(rt) + `","log.level":"info",` +
+			`"message":"hello","ecs.version":"1.6.0"}` + "\n"))
+	})
+})
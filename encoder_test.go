@@ -15,6 +15,9 @@
 package lork
 
 import (
+	"math"
+	"testing"
+
 	"github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -34,6 +37,86 @@ var _ = ginkgo.Describe("json encoder", func() {
 	})
 })
 
+var callerEvent = MakeEvent([]byte(
+	`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00",` +
+		`"caller":"/go/src/github.com/coolerfall/lork/event.go:42",` +
+		`"func":"github.com/coolerfall/lork.MakeEvent","message":"hello"}`,
+))
+var _ = ginkgo.Describe("json encoder with caller", func() {
+	var data []byte
+	rt, _ := appendFormatUnix(data, callerEvent.Timestamp(), TimeFormatRFC3339)
+	ginkgo.It("encode", func() {
+		result := []byte(`{"time":"` + string(rt) + `","level":"INFO","logger_name":"",` +
+			`"caller":"/go/src/github.com/coolerfall/lork/event.go:42",` +
+			`"func":"github.com/coolerfall/lork.MakeEvent","message":"hello"}` + "\n")
+		je := NewJsonEncoder()
+		out, err := je.Encode(callerEvent)
+		Expect(err).To(BeNil())
+		Expect(out).To(Equal(result))
+	})
+})
+
+var _ = ginkgo.Describe("json encoder with options", func() {
+	ginkgo.It("keys and time format", func() {
+		je := NewJsonEncoder(func(o *JsonEncoderOption) {
+			o.TimeKey = "@timestamp"
+			o.LevelKey = "severity"
+			o.MessageKey = "msg"
+			o.TimeFormat = TimeFormatUnixMilli
+			o.LowercaseLevel = true
+			o.OmitEmptyLogger = true
+		})
+		out, err := je.Encode(callerEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"@timestamp":1577414414465,"severity":"info",` +
+			`"caller":"/go/src/github.com/coolerfall/lork/event.go:42",` +
+			`"func":"github.com/coolerfall/lork.MakeEvent","msg":"hello"}` + "\n"))
+	})
+	ginkgo.It("nested and static fields", func() {
+		je := NewJsonEncoder(func(o *JsonEncoderOption) {
+			o.TimeFormat = TimeFormatUnix
+			o.FieldsKey = "fields"
+			o.Fields = map[string]interface{}{"service": "lork", "version": 2}
+			o.OmitEmptyMessage = true
+		})
+		out, err := je.Encode(logEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"time":1577414414,"level":"INFO","logger_name":"",` +
+			`"service":"lork","version":2,"fields":{"key":"value"}}` + "\n"))
+
+		out, err = je.Encode(callerEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"message":"hello","service":"lork","version":2}` + "\n"))
+	})
+})
+
+// fieldsDecoder decodes the json encoder config with given static fields only.
+type fieldsDecoder struct {
+	OptionDecoder
+	fields map[string]interface{}
+}
+
+func (d *fieldsDecoder) Decode(v interface{}) error {
+	v.(*jsonEncoderConfig).Fields = d.fields
+	return nil
+}
+
+var _ = ginkgo.Describe("json encoder from config", func() {
+	ginkgo.It("return error if fields can not be marshalled", func() {
+		_, err := newJsonEncoderFromConfig(&fieldsDecoder{
+			fields: map[string]interface{}{"ratio": math.Inf(1)},
+		})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(HavePrefix("fields: "))
+
+		encoder, err := newJsonEncoderFromConfig(&fieldsDecoder{
+			fields: map[string]interface{}{"service": "lork"},
+		})
+		Expect(err).To(BeNil())
+		Expect(encoder).NotTo(BeNil())
+	})
+})
+
 var _ = ginkgo.Describe("pattern encoder", func() {
 	var data []byte
 	rt, _ := appendFormatUnix(data, logEvent.Timestamp(), "2006-01-02 15:04:05")
@@ -47,3 +130,107 @@ var _ = ginkgo.Describe("pattern encoder", func() {
 		Expect(out).To(Equal(result))
 	})
 })
+
+var _ = ginkgo.Describe("pattern encoder with caller", func() {
+	ginkgo.It("encode", func() {
+		pe := NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#caller #caller{full} #caller{func} #message"
+		})
+		out, err := pe.Encode(callerEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal("lork/event.go:42 " +
+			"/go/src/github.com/coolerfall/lork/event.go:42 " +
+			"github.com/coolerfall/lork.MakeEvent hello\n"))
+	})
+	ginkgo.It("encode without caller", func() {
+		pe := NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#caller #message"
+		})
+		out, err := pe.Encode(logEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal("- -\n"))
+	})
+})
+
+var stackEvent = MakeEvent([]byte(
+	`{"level":"ERROR","time":"2019-12-27T10:40:14.465199844+08:00","message":"hello",` +
+		`"stack":[{"func":"main.work","file":"/go/src/app/main.go","line":12},` +
+		`{"func":"main.main","file":"C:\\app\\main.go","line":6}]}`,
+))
+var _ = ginkgo.Describe("encoder with stack", func() {
+	ginkgo.It("json encode", func() {
+		je := NewJsonEncoder()
+		out, err := je.Encode(stackEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"message":"hello",` +
+			`"stack":[{"func":"main.work","file":"/go/src/app/main.go","line":12},` +
+			`{"func":"main.main","file":"C:\\app\\main.go","line":6}]}` + "\n"))
+	})
+	ginkgo.It("pattern encode", func() {
+		pe := NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#level #message #stack"
+		})
+		out, err := pe.Encode(stackEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal("ERROR hello " +
+			"\n\tmain.work\n\t\t/go/src/app/main.go:12" +
+			"\n\tmain.main\n\t\tC:\\app\\main.go:6\n"))
+	})
+})
+var _ = ginkgo.Describe("pattern encoder with nested fields", func() {
+	ginkgo.It("encode", func() {
+		event := MakeEvent([]byte(`{"level":"INFO","message":"hello",` +
+			`"a":{"b":1,"c":{"d":"e"},"f":{}},"g":[1,2]}`))
+		pe := NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#level #message #fields"
+		})
+		out, err := pe.Encode(event)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal("INFO hello a.b=1 a.c.d=e a.f={} g=[1,2]\n"))
+	})
+	ginkgo.It("encode string without escaping", func() {
+		event := NewLogEvent()
+		event.appendLevel(InfoLevel)
+		event.appendMessage("hello")
+		event.appendString("s", "a\"b\tc")
+		event.appendDict("d", func(r Record) {
+			r.Str("k", `C:\app`)
+		})
+		pe := NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#level #message #fields"
+		})
+		out, err := pe.Encode(event)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal("INFO hello s=a\"b\tc d.k=C:\\app\n"))
+		event.Recycle()
+	})
+})
+
+var _ = ginkgo.Describe("encoders", func() {
+	event := MakeEvent([]byte(`{"level":"WARN","time":"2019-12-27T10:40:14.465199844+08:00",` +
+		`"logger_name":"github.com/lork","caller":"/go/src/app/main.go:42","func":"main.main",` +
+		`"message":"hello \"lork\"","str":"a\tb","int":-12,"float":1.5,"ok":true,"nil":null,` +
+		`"user":{"name":"lork","tags":["a",1]},"empty":[],"error":"read failed",` +
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7",` +
+		`"stack":[{"func":"main.work","file":"/go/src/app/main.go","line":12}]}`))
+
+	ginkgo.DescribeTable("encode without allocation", func(encoder Encoder) {
+		if raceEnabled {
+			ginkgo.Skip("allocations are not stable with race detector")
+		}
+		_, _ = encoder.Encode(event)
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _ = encoder.Encode(event)
+		})
+		Expect(allocs).To(BeZero())
+	},
+		ginkgo.Entry("json", NewJsonEncoder(func(o *JsonEncoderOption) {
+			o.LowercaseLevel = true
+			o.FieldsKey = "fields"
+			o.Fields = map[string]interface{}{"service": "lork"}
+		})),
+		ginkgo.Entry("logfmt", NewLogfmtEncoder()),
+		ginkgo.Entry("ecs", NewEcsEncoder()),
+		ginkgo.Entry("otlp", NewOtlpEncoder()),
+	)
+})
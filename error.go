@@ -0,0 +1,104 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"reflect"
+)
+
+const (
+	errorMessageKey = "message"
+	errorTypeKey    = "type"
+	errorDetailsKey = "details"
+	errorCausesKey  = "causes"
+
+	// maxErrorDepth is the max depth to unwrap the causes of error.
+	maxErrorDepth = 16
+)
+
+// EncodeError adds error into record with its message, concrete type, details of
+// ErrorMarshaler and the causes unwrapped with Unwrap() error or Unwrap() []error.
+// Providers can use this to keep the same error format with lork.
+func EncodeError(r Record, err error) {
+	if err == nil {
+		r.Any(ErrorFieldKey, nil)
+		return
+	}
+
+	r.Str(ErrorFieldKey, err.Error()).Str(ErrorTypeFieldKey, errorType(err))
+	if m, ok := err.(ErrorMarshaler); ok {
+		r.Dict(ErrorDetailsFieldKey, m.MarshalLogError)
+	}
+	if hasErrorCauses(err) {
+		r.Array(ErrorCausesFieldKey, &errorCauses{err: err})
+	}
+}
+
+// ErrorArray creates an ArrayMarshaler which encodes each error as an object.
+func ErrorArray(errs []error) ArrayMarshaler {
+	return ArrayMarshalerFunc(func(a ArrayEncoder) {
+		for _, err := range errs {
+			a.Err(err)
+		}
+	})
+}
+
+// errorCauses is an ArrayMarshaler which encodes the causes of error.
+type errorCauses struct {
+	err error
+}
+
+func (c *errorCauses) MarshalLogArray(a ArrayEncoder) {
+	if ae, ok := a.(*arrayEncoder); ok {
+		ae.enc.writeErrorCauses(c.err, 1)
+		return
+	}
+
+	walkErrorCauses(c.err, func(cause error) {
+		a.Err(cause)
+	})
+}
+
+// errorType returns the name of concrete type of error.
+func errorType(err error) string {
+	return reflect.TypeOf(err).String()
+}
+
+func hasErrorCauses(err error) bool {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap() != nil
+	case interface{ Unwrap() []error }:
+		return len(e.Unwrap()) > 0
+	}
+
+	return false
+}
+
+// walkErrorCauses walks through the causes directly wrapped in error.
+func walkErrorCauses(err error, f func(cause error)) {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			f(cause)
+		}
+	case interface{ Unwrap() []error }:
+		for _, cause := range e.Unwrap() {
+			if cause != nil {
+				f(cause)
+			}
+		}
+	}
+}
@@ -18,7 +18,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"math"
+	"runtime"
 	"strconv"
 	"sync"
 	"time"
@@ -31,11 +31,16 @@ type LogEvent struct {
 	level       *bytes.Buffer
 	loggerName  *bytes.Buffer
 	caller      *bytes.Buffer
+	callerFunc  *bytes.Buffer
+	stack       *bytes.Buffer
 	message     *bytes.Buffer
 	fields      *bytes.Buffer
 	fieldsIndex *bytes.Buffer
 	appender    *bytes.Buffer
 	tmp         *bytes.Buffer
+	nested      *nestedEncoder
+	path        []byte
+	lazies      []lazyField
 }
 
 var (
@@ -47,11 +52,14 @@ var (
 				level:       new(bytes.Buffer),
 				loggerName:  new(bytes.Buffer),
 				caller:      new(bytes.Buffer),
+				callerFunc:  new(bytes.Buffer),
+				stack:       new(bytes.Buffer),
 				message:     new(bytes.Buffer),
 				fields:      new(bytes.Buffer),
 				fieldsIndex: new(bytes.Buffer),
 				appender:    new(bytes.Buffer),
 				tmp:         tmp,
+				nested:      newNestedEncoder(),
 			}
 		},
 	}
@@ -79,6 +87,12 @@ func MakeEvent(p []byte) *LogEvent {
 			event.appendLogger(v)
 		case MessageFieldKey:
 			event.appendMessageBytes(v)
+		case CallerFieldKey:
+			event.appendCallerBytes(v)
+		case FunctionFieldKey:
+			event.appendCallerFuncBytes(v)
+		case StackFieldKey:
+			event.appendStackBytes(v)
 
 		default:
 			event.makeFields(k, v, dataType == jsonparser.String)
@@ -90,15 +104,19 @@ func MakeEvent(p []byte) *LogEvent {
 	return event
 }
 
+// Copy copies a new LogEvent. The lazy fields are copied without being resolved.
 func (e *LogEvent) Copy() *LogEvent {
 	cp := eventPool.Get().(*LogEvent)
 	cp.unixNano = e.unixNano
 	cp.level.Write(e.level.Bytes())
 	cp.loggerName.Write(e.loggerName.Bytes())
 	cp.caller.Write(e.caller.Bytes())
+	cp.callerFunc.Write(e.callerFunc.Bytes())
+	cp.stack.Write(e.stack.Bytes())
 	cp.message.Write(e.message.Bytes())
 	cp.fields.Write(e.fields.Bytes())
 	cp.fieldsIndex.Write(e.fieldsIndex.Bytes())
+	cp.lazies = append(cp.lazies, e.lazies...)
 
 	return cp
 }
@@ -126,12 +144,29 @@ func (e *LogEvent) LoggerName() []byte {
 	return e.loggerName.Bytes()
 }
 
+// Caller returns the file and line of caller, e.g. /path/to/file.go:42.
+func (e *LogEvent) Caller() []byte {
+	return e.caller.Bytes()
+}
+
+// CallerFunc returns the function name of caller.
+func (e *LogEvent) CallerFunc() []byte {
+	return e.callerFunc.Bytes()
+}
+
+// Stack returns the stack trace as json array of frames, each frame contains
+// func, file and line, e.g. [{"func":"main.main","file":"/path/to/main.go","line":42}].
+func (e *LogEvent) Stack() []byte {
+	return e.stack.Bytes()
+}
+
 // Message returns message bytes.
 func (e *LogEvent) Message() []byte {
 	return e.message.Bytes()
 }
 
-// Fields gets extra key and value bytes.
+// Fields gets extra key and value bytes. The lazy fields are not included until they
+// are resolved when the event is written by MultiWriter.
 func (e *LogEvent) Fields(callback func(k, v []byte, isString bool) error) error {
 	var startIndex = 0
 	var kvIndex = 0
@@ -164,6 +199,75 @@ func (e *LogEvent) Fields(callback func(k, v []byte, isString bool) error) error
 	return nil
 }
 
+// WalkFields walks through all the fields like Fields, but members of nested object
+// will be flattened with dotted path, e.g. {"a":{"b":1}} will be walked as a.b and 1.
+// The path is only valid in callback.
+func (e *LogEvent) WalkFields(callback func(path, v []byte, isString bool) error) error {
+	return e.Fields(func(k, v []byte, isString bool) error {
+		e.path = append(e.path[:0], k...)
+		return e.walkField(v, isString, callback)
+	})
+}
+
+func (e *LogEvent) walkField(v []byte, isString bool,
+	callback func(path, v []byte, isString bool) error) error {
+	if isString || !isJsonObject(v) {
+		return callback(e.path, v, isString)
+	}
+
+	n := len(e.path)
+	defer func() {
+		e.path = e.path[:n]
+	}()
+
+	return jsonparser.ObjectEach(v, func(k []byte, mv []byte,
+		dataType jsonparser.ValueType, _ int) error {
+		e.path = append(append(e.path[:n], '.'), k...)
+		return e.walkField(mv, dataType == jsonparser.String, callback)
+	})
+}
+
+// AddField adds a field with any value into this event.
+func (e *LogEvent) AddField(key string, val interface{}) {
+	e.appendAny(key, val)
+}
+
+// RenameField renames all the fields with given key. It returns false if not found.
+func (e *LogEvent) RenameField(key, newKey string) bool {
+	return e.rewriteField(key, newKey, false)
+}
+
+// RemoveField removes all the fields with given key. It returns false if not found.
+func (e *LogEvent) RemoveField(key string) bool {
+	return e.rewriteField(key, "", true)
+}
+
+// rewriteField renames or removes the fields with given key by rewriting all fields.
+func (e *LogEvent) rewriteField(key, newKey string, remove bool) bool {
+	var found bool
+	dst := NewLogEvent()
+	_ = e.Fields(func(k, v []byte, isString bool) error {
+		if string(k) != key {
+			dst.makeFields(k, v, isString)
+			return nil
+		}
+
+		found = true
+		if !remove {
+			dst.appendKeyValue(newKey, v, isString)
+		}
+		return nil
+	})
+
+	if found {
+		e.fields, dst.fields = dst.fields, e.fields
+		e.fieldsIndex, dst.fieldsIndex = dst.fieldsIndex, e.fieldsIndex
+	}
+	dst.Recycle()
+
+	return found
+}
+
 func (e *LogEvent) appendLevel(lvl Level) {
 	e.tmp.WriteString(lvl.String())
 	data := e.tmp.Bytes()
@@ -181,6 +285,45 @@ func (e *LogEvent) appendLogger(v []byte) {
 	e.loggerName.Write(v)
 }
 
+func (e *LogEvent) appendCaller(skip int) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return
+	}
+
+	e.caller.Reset()
+	e.caller.WriteString(file)
+	e.caller.WriteByte(':')
+	data := e.tmp.Bytes()
+	data = strconv.AppendInt(data, int64(line), 10)
+	e.caller.Write(data)
+
+	e.callerFunc.Reset()
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		e.callerFunc.WriteString(fn.Name())
+	}
+}
+
+func (e *LogEvent) appendCallerBytes(v []byte) {
+	e.caller.Reset()
+	e.caller.Write(v)
+}
+
+func (e *LogEvent) appendCallerFuncBytes(v []byte) {
+	e.callerFunc.Reset()
+	e.callerFunc.Write(v)
+}
+
+func (e *LogEvent) appendStack(skip int) {
+	e.stack.Reset()
+	e.stack.Write(appendStackTrace(e.tmp.Bytes(), skip+1))
+}
+
+func (e *LogEvent) appendStackBytes(v []byte) {
+	e.stack.Reset()
+	e.stack.Write(v)
+}
+
 func (e *LogEvent) makeFields(k, v []byte, isString bool) {
 	e.fields.Write(k)
 	e.fields.Write(v)
@@ -221,6 +364,31 @@ func (e *LogEvent) appendMessage(msg string) {
 	e.appendMessageBytes(v)
 }
 
+// appendFields appends all encoded fields of other event to this event.
+func (e *LogEvent) appendFields(other *LogEvent) {
+	e.fields.Write(other.fields.Bytes())
+	e.fieldsIndex.Write(other.fieldsIndex.Bytes())
+	e.lazies = append(e.lazies, other.lazies...)
+}
+
+func (e *LogEvent) appendLazy(key string, valuer LogValuer) {
+	e.lazies = append(e.lazies, lazyField{key: key, valuer: valuer})
+}
+
+// resolveLazies resolves all the lazy fields and appends them after other fields.
+func (e *LogEvent) resolveLazies() {
+	if len(e.lazies) == 0 {
+		return
+	}
+
+	lazies := e.lazies
+	e.lazies = e.lazies[:0]
+	for i := range lazies {
+		e.appendAny(lazies[i].key, ResolveLogValue(lazies[i].valuer))
+		lazies[i] = lazyField{}
+	}
+}
+
 func (e *LogEvent) appendKeyValue(key string, value []byte, isString bool) {
 	e.fields.WriteString(key)
 	e.fieldsIndex.WriteString(strconv.Itoa(len(key)))
@@ -258,22 +426,27 @@ func (e *LogEvent) appendArray(key string, len int, f func(data []byte, index in
 }
 
 func (e *LogEvent) appendString(key, value string) {
-	if key == LoggerNameFieldKey {
+	switch key {
+	case LoggerNameFieldKey:
 		e.loggerName.WriteString(value)
 		return
+	case CallerFieldKey:
+		e.caller.Reset()
+		e.caller.WriteString(value)
+		return
+	case FunctionFieldKey:
+		e.callerFunc.Reset()
+		e.callerFunc.WriteString(value)
+		return
 	}
-	e.appender.WriteString(value)
-	data := e.appender.Bytes()
-	e.appender.Reset()
+	e.appender.Grow(len(value))
+	data := appendEscapedString(e.appender.Bytes(), value)
 	e.appendKeyValue(key, data, true)
 }
 
 func (e *LogEvent) appendStrings(key string, value []string) {
 	e.appendArray(key, len(value), func(data []byte, index int) ([]byte, bool) {
-		e.tmp.WriteString(value[index])
-		d := e.tmp.Bytes()
-		e.tmp.Reset()
-		return d, true
+		return appendEscapedString(data, value[index]), true
 	})
 }
 
@@ -380,16 +553,7 @@ func (e *LogEvent) appendUints64(key string, value []uint64) {
 }
 
 func (e *LogEvent) appendFloat(dst []byte, value float64, bitSize int) []byte {
-	switch {
-	case math.IsNaN(value):
-		return append(dst, `"NaN"`...)
-	case math.IsInf(value, 1):
-		return append(dst, `"+Inf"`...)
-	case math.IsInf(value, -1):
-		return append(dst, `"-Inf"`...)
-	default:
-		return strconv.AppendFloat(dst, value, 'f', -1, bitSize)
-	}
+	return appendJsonFloat(dst, value, bitSize)
 }
 
 func (e *LogEvent) appendFloat32(key string, value float32) {
@@ -446,16 +610,33 @@ func (e *LogEvent) appendDurations(key string, value []time.Duration) {
 }
 
 func (e *LogEvent) appendErrors(key string, value []error) {
-	e.appendArray(key, len(value), func(data []byte, index int) ([]byte, bool) {
-		e.tmp.WriteString(value[index].Error())
-		d := e.tmp.Bytes()
-		e.tmp.Reset()
-		return d, true
-	})
+	e.nested.writeSlice(len(value), func(i int) { e.nested.writeError(value[i], 0) })
+	e.appendKeyValue(key, e.nested.buf.Bytes(), false)
+	e.nested.reset()
+}
+
+func (e *LogEvent) appendDict(key string, dict func(r Record)) {
+	e.nested.writeDict(dict)
+	e.appendKeyValue(key, e.nested.buf.Bytes(), false)
+	e.nested.reset()
+}
+
+func (e *LogEvent) appendObject(key string, obj ObjectMarshaler) {
+	e.nested.writeObject(obj)
+	e.appendKeyValue(key, e.nested.buf.Bytes(), false)
+	e.nested.reset()
+}
+
+func (e *LogEvent) appendArrayMarshaler(key string, arr ArrayMarshaler) {
+	e.nested.writeArray(arr)
+	e.appendKeyValue(key, e.nested.buf.Bytes(), false)
+	e.nested.reset()
 }
 
 func (e *LogEvent) appendAny(key string, val interface{}) {
 	switch val.(type) {
+	case LogValuer:
+		e.appendLazy(key, val.(LogValuer))
 	case string:
 		e.appendString(key, val.(string))
 	case []string:
@@ -518,6 +699,10 @@ func (e *LogEvent) appendAny(key string, val interface{}) {
 		e.appendDuration(key, val.(time.Duration))
 	case []time.Duration:
 		e.appendDurations(key, val.([]time.Duration))
+	case ObjectMarshaler:
+		e.appendObject(key, val.(ObjectMarshaler))
+	case ArrayMarshaler:
+		e.appendArrayMarshaler(key, val.(ArrayMarshaler))
 	default:
 		data, err := json.Marshal(val)
 		if err != nil {
@@ -533,10 +718,18 @@ func (e *LogEvent) Recycle() {
 	e.level.Reset()
 	e.loggerName.Reset()
 	e.caller.Reset()
+	e.callerFunc.Reset()
+	e.stack.Reset()
 	e.message.Reset()
 	e.fields.Reset()
 	e.fieldsIndex.Reset()
 	e.appender.Reset()
 	e.tmp.Reset()
+	e.nested.reset()
+	e.path = e.path[:0]
+	for i := range e.lazies {
+		e.lazies[i] = lazyField{}
+	}
+	e.lazies = e.lazies[:0]
 	eventPool.Put(e)
 }
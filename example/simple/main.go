@@ -106,6 +106,9 @@ func main() {
 				"name": "dog",
 				"age":  2,
 			}).Msg("this is interface")
+			logger.With(func(r lork.Record) {
+				r.Str("request_id", "lork-request")
+			}).Info().Msg("this is with bound fields")
 		}()
 	}
 	lork.LoggerC().Info().Bytes("bytes", []byte("ABCK")).Msg("test for auto logger name")
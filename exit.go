@@ -0,0 +1,61 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// terminateFlushTimeout is the max duration to flush writers before exiting or panicking.
+const terminateFlushTimeout = 5 * time.Second
+
+var exitFunc atomic.Value
+
+// SetExitFunc replaces the function called to exit the process after a record of
+// FatalLevel is written, os.Exit is used if exit is nil. It's useful in tests.
+func SetExitFunc(exit func(code int)) {
+	if exit == nil {
+		exit = os.Exit
+	}
+	exitFunc.Store(exit)
+}
+
+// Terminate flushes all the writers of bound provider, then calls the exit function
+// for FatalLevel, or panics with the message for PanicLevel. It does nothing for
+// other levels. Providers should call this after a record is written.
+func Terminate(lvl Level, msg string) {
+	if lvl != FatalLevel && lvl != PanicLevel {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), terminateFlushTimeout)
+	if err := flushBound(ctx); err != nil {
+		Reportf("flush writers before terminating error: %v", err)
+	}
+	cancel()
+
+	if lvl == PanicLevel {
+		panic(msg)
+	}
+
+	exit, ok := exitFunc.Load().(func(code int))
+	if !ok {
+		exit = os.Exit
+	}
+	exit(1)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("fatal and panic", func() {
+	var gated *gatedWriter
+	var code int
+
+	ginkgo.BeforeEach(func() {
+		Reset()
+		gated = &gatedWriter{gate: make(chan struct{})}
+		aw := NewAsyncWriter(func(o *AsyncWriterOption) {
+			o.Name = "ASYNC"
+		})
+		aw.AddWriter(gated)
+		Manual().AddWriter(aw)
+
+		code = 0
+		SetExitFunc(func(c int) {
+			code = c
+		})
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(gated.gate)
+		}()
+	})
+
+	ginkgo.AfterEach(func() {
+		SetExitFunc(nil)
+		Reset()
+	})
+
+	ginkgo.It("fatal flushes and exits", func() {
+		Logger("github.com/coolerfall/lork").Fatal().Msg("bye")
+		Expect(code).To(Equal(1))
+		Expect(gated.messages).To(ContainElement("bye"))
+	})
+	ginkgo.It("panic flushes and panics", func() {
+		Expect(func() {
+			Logger("github.com/coolerfall/lork").Panic().Msg("boom")
+		}).To(PanicWith("boom"))
+		Expect(code).To(Equal(0))
+		Expect(gated.messages).To(ContainElement("boom"))
+	})
+	ginkgo.It("terminate even if level is disabled", func() {
+		logger := Logger("github.com/coolerfall/lork/off")
+		logger.SetLevel(OffLevel)
+		logger.Fatal().Msgf("bye %d", 1)
+		Expect(code).To(Equal(1))
+		Expect(func() {
+			logger.Panic().Msg("boom")
+		}).To(PanicWith("boom"))
+		Expect(gated.messages).NotTo(ContainElement("bye 1"))
+		Expect(gated.messages).NotTo(ContainElement("boom"))
+	})
+	ginkgo.It("other levels", func() {
+		Logger("github.com/coolerfall/lork").Error().Msg("error")
+		Expect(code).To(Equal(0))
+	})
+})
@@ -15,6 +15,7 @@
 package lork
 
 import (
+	"context"
 	"sync"
 )
 
@@ -31,6 +32,14 @@ var (
 	}
 )
 
+// shutdownable represents a logger factory whose writers can be flushed and stopped.
+type shutdownable interface {
+	Flushable
+
+	// Shutdown flushes and stops all the writers.
+	Shutdown(ctx context.Context) error
+}
+
 type loggerFactory struct {
 	initialState  int
 	lock          sync.Mutex
@@ -57,6 +66,32 @@ func Reset() {
 	factory.Reset()
 }
 
+// Flush blocks until all the writers of bound provider are flushed, including the
+// queued events in async and socket writers and in-flight archiving.
+func Flush() error {
+	return flushBound(context.Background())
+}
+
+// Shutdown flushes and stops all the writers of bound provider, it should be called
+// before the process exits. The returned error reports what could not be flushed
+// before the deadline of given context.
+func Shutdown(ctx context.Context) error {
+	if sd := factory.shutdownable(); sd != nil {
+		return sd.Shutdown(ctx)
+	}
+
+	return nil
+}
+
+// flushBound flushes all the writers of bound provider until the context is done.
+func flushBound(ctx context.Context) error {
+	if sd := factory.shutdownable(); sd != nil {
+		return sd.Flush(ctx)
+	}
+
+	return nil
+}
+
 func getLoggerFactory() ILoggerFactory {
 	return factory.provider().LoggerFactory()
 }
@@ -76,6 +111,16 @@ func (f *loggerFactory) Reset() {
 	f.initialState = stateUninitialized
 }
 
+// shutdownable returns the logger factory of bound provider if it can be shutdown.
+func (f *loggerFactory) shutdownable() shutdownable {
+	if f.initialState != stateSuccess {
+		return nil
+	}
+	sd, _ := f.boundProvider.LoggerFactory().(shutdownable)
+
+	return sd
+}
+
 func (f *loggerFactory) provider() Provider {
 	if f.initialState == stateUninitialized {
 		f.lock.Lock()
@@ -111,9 +156,7 @@ func (f *loggerFactory) bind() {
 	}
 
 	for _, b := range f.bridges {
-		if provider.Name() == b.Name() {
-			ReportfExit("cycle checked, %s -> lork -> %s", b.Name(), provider.Name())
-		}
+		f.checkCycle(provider, b)
 	}
 
 	f.boundProvider = provider
@@ -160,5 +203,20 @@ func (f *loggerFactory) Load(provider Provider) {
 }
 
 func (f *loggerFactory) Install(bridge Bridge) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.initialState == stateSuccess {
+		// the provider has been bound, check it directly
+		f.checkCycle(f.boundProvider, bridge)
+	}
 	f.bridges = append(f.bridges, bridge)
 }
+
+// checkCycle checks if the bridge and provider are the same logging framework,
+// which will cause infinite loop.
+func (f *loggerFactory) checkCycle(provider Provider, bridge Bridge) {
+	if provider.Name() == bridge.Name() {
+		ReportfExit("cycle checked, %s -> lork -> %s", bridge.Name(), provider.Name())
+	}
+}
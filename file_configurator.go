@@ -0,0 +1,536 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError represents the problems found in config file.
+type ConfigError struct {
+	Filename string
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config file %s: %s", e.Filename, strings.Join(e.Problems, "; "))
+}
+
+type configFile struct {
+	Writers []ComponentConfig `json:"writers" yaml:"writers"`
+	Loggers []configLogger    `json:"loggers" yaml:"loggers"`
+}
+
+type configLogger struct {
+	Name     string   `json:"name" yaml:"name"`
+	Level    string   `json:"level" yaml:"level"`
+	Additive *bool    `json:"additive" yaml:"additive"`
+	Writers  []string `json:"writers" yaml:"writers"`
+}
+
+// configuredLogger is a logger built from config file.
+type configuredLogger struct {
+	name     string
+	level    Level
+	hasLevel bool
+	additive bool
+	writers  []Writer
+}
+
+// FileConfiguratorOption represents available options for file configurator.
+type FileConfiguratorOption struct {
+	// ScanPeriod is the period to check if the config file is changed, the config
+	// will be reloaded once changed. Scanning is disabled if it's not positive.
+	ScanPeriod time.Duration
+}
+
+// FileConfigurator configures writers, encoders, filters, rolling policies and levels
+// of loggers with a config file in JSON or YAML format.
+type FileConfigurator struct {
+	locker   sync.Mutex
+	filename string
+	opts     *FileConfiguratorOption
+	config   *configBuilder
+	context  *LoggerContext
+	modTime  time.Time
+	size     int64
+	stop     chan struct{}
+}
+
+// NewFileConfigurator loads the config file, the format is decided by the extension
+// which should be .json, .yaml or .yml. All the writers are built once loaded, and
+// a ConfigError with detailed problems will be returned if the config is invalid.
+func NewFileConfigurator(filename string,
+	options ...func(*FileConfiguratorOption)) (*FileConfigurator, error) {
+	opts := &FileConfiguratorOption{}
+	for _, f := range options {
+		f(opts)
+	}
+
+	c := &FileConfigurator{
+		filename: filename,
+		opts:     opts,
+	}
+	b, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.config = b
+
+	return c, nil
+}
+
+// Configure attaches the writers and sets the levels of loggers, and starts scanning
+// the config file if ScanPeriod is set. It returns StatusNoNext if any writer is
+// attached to root logger.
+func (c *FileConfigurator) Configure(ctx *LoggerContext) ExecutionStatus {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	c.context = ctx
+	if err := c.apply(nil, c.config); err != nil {
+		ReportfExit("apply config file error: %v", err)
+	}
+	if c.opts.ScanPeriod > 0 && c.stop == nil {
+		c.stop = make(chan struct{})
+		go c.scan(c.stop)
+	}
+
+	for _, cl := range c.config.loggers {
+		if ctx.RealLogger(cl.name).isRootLogger() && len(cl.writers) > 0 {
+			return StatusNoNext
+		}
+	}
+
+	return StatusNext
+}
+
+// Reload loads the config file again and swaps the writers into loggers without
+// dropping or duplicating any event: the new writers are started first, then swapped
+// in atomically, and the old ones are stopped after the queued events are drained.
+// The current config will be kept if the config file is invalid or any new writer
+// fails to start.
+func (c *FileConfigurator) Reload() error {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	b, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	if c.context != nil {
+		if err = c.apply(c.config, b); err != nil {
+			return err
+		}
+	}
+	c.config = b
+
+	return nil
+}
+
+// Stop stops scanning the config file.
+func (c *FileConfigurator) Stop() {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// load reads and parses the config file, and records its modification.
+func (c *FileConfigurator) load() (*configBuilder, error) {
+	if info, err := os.Stat(c.filename); err == nil {
+		c.modTime, c.size = info.ModTime(), info.Size()
+	}
+
+	data, err := os.ReadFile(c.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfig(c.filename, data)
+}
+
+// changed checks if the config file is modified since last loaded.
+func (c *FileConfigurator) changed() bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	info, err := os.Stat(c.filename)
+	if err != nil {
+		return false
+	}
+
+	return !info.ModTime().Equal(c.modTime) || info.Size() != c.size
+}
+
+func (c *FileConfigurator) scan(stop chan struct{}) {
+	ticker := time.NewTicker(c.opts.ScanPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !c.changed() {
+				continue
+			}
+			if err := c.Reload(); err != nil {
+				Reportf("reload config file error: %v", err)
+			}
+		}
+	}
+}
+
+// apply replaces the writers and levels configured with old config by the new ones.
+// Nothing will be replaced if any new writer fails to start.
+func (c *FileConfigurator) apply(old, config *configBuilder) error {
+	// start the new writers before swapping
+	if err := config.start(); err != nil {
+		return err
+	}
+
+	// the route name of root logger is empty
+	routeName := func(name string) string {
+		logger := c.context.RealLogger(name)
+		if logger.isRootLogger() {
+			return ""
+		}
+		return logger.name
+	}
+	var retiredWriters []Writer
+	retired := make(map[Writer]bool)
+	oldRoutes := make(map[string]bool)
+	if old != nil {
+		for _, cl := range old.loggers {
+			for _, w := range cl.writers {
+				retired[w] = true
+			}
+			retiredWriters = append(retiredWriters, cl.writers...)
+			oldRoutes[routeName(cl.name)] = true
+		}
+	}
+	newRoutes := make(map[string]configuredLogger)
+	for _, cl := range config.loggers {
+		newRoutes[routeName(cl.name)] = cl
+	}
+
+	c.context.rootLogger.multiWriter.swap(func(writers []Writer,
+		named map[string]*loggerWriters) ([]Writer, map[string]*loggerWriters) {
+		replace := func(writers []Writer, name string) []Writer {
+			kept := make([]Writer, 0, len(writers))
+			for _, w := range writers {
+				if !retired[w] {
+					kept = append(kept, w)
+				}
+			}
+			return append(kept, newRoutes[name].writers...)
+		}
+
+		swapped := make(map[string]*loggerWriters, len(named)+len(newRoutes))
+		for name, lw := range named {
+			swapped[name] = lw
+		}
+		for name := range oldRoutes {
+			if _, ok := newRoutes[name]; !ok && len(name) != 0 {
+				if lw, ok := swapped[name]; ok {
+					swapped[name] = &loggerWriters{writers: replace(lw.writers, name),
+						additive: true}
+				}
+			}
+		}
+		for name, cl := range newRoutes {
+			if len(name) == 0 {
+				continue
+			}
+			lw := &loggerWriters{additive: cl.additive}
+			if v, ok := swapped[name]; ok {
+				lw.writers = replace(v.writers, name)
+			} else {
+				lw.writers = cl.writers
+			}
+			swapped[name] = lw
+		}
+
+		return replace(writers, ""), swapped
+	})
+	stopWriters(context.Background(), retiredWriters)
+
+	// the loggers not configured any more inherit level again
+	if old != nil {
+		for _, cl := range old.loggers {
+			if nc, ok := newRoutes[routeName(cl.name)]; cl.hasLevel && (!ok || !nc.hasLevel) {
+				c.context.RealLogger(cl.name).ResetLevel()
+			}
+		}
+	}
+	for _, cl := range config.loggers {
+		if cl.hasLevel {
+			c.context.RealLogger(cl.name).SetLevel(cl.level)
+		}
+	}
+	// the spec in environment variable always wins
+	applyEnvLevels(c.context)
+
+	return nil
+}
+
+// parseConfig decodes config data and builds the loggers with writers.
+func parseConfig(filename string, data []byte) (*configBuilder, error) {
+	var cf configFile
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cf)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&cf)
+	default:
+		err = fmt.Errorf("unsupported config format %q, use .json, .yaml or .yml",
+			filepath.Ext(filename))
+	}
+	if err != nil {
+		return nil, &ConfigError{Filename: filename, Problems: []string{err.Error()}}
+	}
+
+	b := newConfigBuilder(&cf)
+	b.build()
+	if len(b.problems) > 0 {
+		return nil, &ConfigError{Filename: filename, Problems: b.problems}
+	}
+
+	return b, nil
+}
+
+// configApply is the function registered by writer to run when config is applied.
+type configApply struct {
+	writer string
+	apply  func() error
+}
+
+// configBuilder builds writers and loggers from config, and collects the problems.
+type configBuilder struct {
+	config     *configFile
+	writers    map[string]int
+	built      map[string]Writer
+	building   map[string]bool
+	referenced map[string]string
+	applies    []configApply
+	loggers    []configuredLogger
+	problems   []string
+}
+
+func newConfigBuilder(cf *configFile) *configBuilder {
+	return &configBuilder{
+		config:     cf,
+		writers:    make(map[string]int),
+		built:      make(map[string]Writer),
+		building:   make(map[string]bool),
+		referenced: make(map[string]string),
+	}
+}
+
+func (b *configBuilder) problemf(format string, args ...interface{}) {
+	b.problems = append(b.problems, fmt.Sprintf(format, args...))
+}
+
+func (b *configBuilder) build() {
+	for i, wc := range b.config.Writers {
+		name := writerName(wc)
+		if len(name) == 0 {
+			b.problemf("writers[%d]: missing name", i)
+			continue
+		}
+		if _, ok := b.writers[name]; ok {
+			b.problemf("writers[%d]: duplicate writer name [%s]", i, name)
+			continue
+		}
+		b.writers[name] = i
+	}
+
+	names := make(map[string]bool)
+	for i, lc := range b.config.Loggers {
+		path := fmt.Sprintf("loggers[%d]", i)
+		if len(lc.Name) == 0 {
+			b.problemf("%s: missing name", path)
+			continue
+		}
+		path = fmt.Sprintf("%s (%s)", path, lc.Name)
+		if names[lc.Name] {
+			b.problemf("%s: duplicate logger name", path)
+			continue
+		}
+		names[lc.Name] = true
+
+		cl := configuredLogger{
+			name:     lc.Name,
+			additive: lc.Additive == nil || *lc.Additive,
+		}
+		if len(lc.Level) != 0 {
+			if cl.level, cl.hasLevel = LookupLevel(lc.Level); !cl.hasLevel {
+				b.problemf("%s: invalid level %q", path, lc.Level)
+			}
+		}
+		for _, name := range lc.Writers {
+			w, err := b.refWriter(path, name)
+			if err != nil {
+				b.problemf("%s: %v", path, err)
+			} else if w != nil {
+				cl.writers = append(cl.writers, w)
+			}
+		}
+		b.loggers = append(b.loggers, cl)
+	}
+
+	for i, wc := range b.config.Writers {
+		name := writerName(wc)
+		if _, ok := b.referenced[name]; !ok && len(name) != 0 {
+			b.problemf("writers[%d] (%s): not referenced by any logger or writer", i, name)
+		}
+	}
+}
+
+// refWriters builds the writers referenced by name.
+func (b *configBuilder) refWriters(path string, names []string) ([]Writer, error) {
+	writers := make([]Writer, 0, len(names))
+	for _, name := range names {
+		w, err := b.refWriter(path, name)
+		if err != nil {
+			return nil, err
+		}
+		if w != nil {
+			writers = append(writers, w)
+		}
+	}
+
+	return writers, nil
+}
+
+// refWriter builds the writer referenced by name, each writer can only be referenced
+// once since it will be started when attached. Nil will be returned if the writer
+// is invalid, and the problems are reported with the writer itself.
+func (b *configBuilder) refWriter(path, name string) (Writer, error) {
+	if by, ok := b.referenced[name]; ok {
+		return nil, fmt.Errorf("writer [%s] is already referenced by %s", name, by)
+	}
+	index, ok := b.writers[name]
+	if !ok {
+		return nil, fmt.Errorf("no such writer [%s]", name)
+	}
+	b.referenced[name] = path
+
+	return b.buildWriter(index), nil
+}
+
+func (b *configBuilder) buildWriter(index int) Writer {
+	wc := b.config.Writers[index]
+	name := writerName(wc)
+	path := fmt.Sprintf("writers[%d] (%s)", index, name)
+	if b.building[name] {
+		b.problemf("%s: cycle reference", path)
+		return nil
+	}
+	b.building[name] = true
+	defer delete(b.building, name)
+
+	d := &componentDecoder{builder: b, path: path, name: name, config: wc}
+	factory, err := d.lookup(writerKind, wc)
+	if err != nil {
+		b.problemf("%s: %v", path, err)
+		return nil
+	}
+	w, err := factory.(WriterFactory)(d)
+	if err != nil {
+		b.problemf("%s: %v", path, err)
+		return nil
+	}
+	b.built[name] = w
+
+	return w
+}
+
+// start runs the applies and starts the writers of loggers, the wrapped ones first.
+// All the writers started or attached will be stopped if any of them fails to start.
+func (b *configBuilder) start() error {
+	var started []Writer
+	rollback := func() {
+		for i := len(started) - 1; i >= 0; i-- {
+			stopContext(context.Background(), started[i])
+		}
+	}
+
+	for _, a := range b.applies {
+		if err := a.apply(); err != nil {
+			rollback()
+			return fmt.Errorf("apply writer [%s] error: %w", a.writer, err)
+		}
+		started = append(started, b.built[a.writer])
+	}
+	for _, cl := range b.loggers {
+		for _, w := range cl.writers {
+			if err := startChecked(w); err != nil {
+				rollback()
+				return fmt.Errorf("start writer [%s] error: %w", w.Name(), err)
+			}
+			started = append(started, w)
+		}
+	}
+
+	return nil
+}
+
+// writerName returns the name of writer in config.
+func writerName(c ComponentConfig) string {
+	name, _ := c["name"].(string)
+
+	return name
+}
+
+// checkFilenamePattern checks if the filename pattern is valid for rolling policy.
+func checkFilenamePattern(pattern string, withIndex bool) error {
+	fp, err := newFilenamePattern(pattern)
+	switch {
+	case len(pattern) == 0:
+		return errors.New("missing filename_pattern")
+	case err != nil:
+		return fmt.Errorf("invalid filename_pattern %q: %v", pattern, err)
+	case len(fp.datePattern()) == 0:
+		return fmt.Errorf("filename_pattern %q missing #date", pattern)
+	case withIndex && !fp.hasIndexConverter():
+		return fmt.Errorf("filename_pattern %q missing #index", pattern)
+	case !withIndex && fp.hasIndexConverter():
+		return fmt.Errorf("filename_pattern %q should not contain #index", pattern)
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("file configurator", func() {
+	var dir string
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "lork-config")
+		Expect(err).To(BeNil())
+	})
+	ginkgo.AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	writeConfig := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		Expect(os.WriteFile(filename, []byte(content), 0644)).To(BeNil())
+		return filename
+	}
+
+	ginkgo.It("configure with yaml", func() {
+		filename := writeConfig("lork.yaml", `
+writers:
+  - name: CONSOLE
+    type: console
+    encoder:
+      type: pattern
+      pattern: "#level #message"
+  - name: ASYNC
+    type: async
+    queue_size: 256
+    writers: [FILE]
+  - name: FILE
+    type: file
+    filename: `+filepath.Join(dir, "lork.log")+`
+    encoder:
+      type: json
+    filter:
+      type: threshold
+      level: info
+    rolling_policy:
+      type: size_and_time_based
+      filename_pattern: `+filepath.Join(dir, "lork.#date{2006-01-02}.#index.log")+`
+      max_file_size: 10MB
+      max_history: 3
+loggers:
+  - name: ROOT
+    level: warn
+    writers: [CONSOLE]
+  - name: github.com/acme/db
+    level: debug
+    additive: false
+    writers: [ASYNC]
+`)
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		Expect(fc.Configure(ctx)).To(Equal(StatusNoNext))
+
+		root := ctx.RealLogger(RootLoggerName)
+		Expect(root.EffectiveLevel()).To(Equal(WarnLevel))
+		Expect(root.GetWriter("CONSOLE")).NotTo(BeNil())
+
+		db := ctx.RealLogger("github.com/acme/db")
+		Expect(db.EffectiveLevel()).To(Equal(DebugLevel))
+		aw, ok := db.GetWriter("ASYNC").(*AsyncWriter)
+		Expect(ok).To(BeTrue())
+		Expect(aw.GetWriter("FILE")).NotTo(BeNil())
+	})
+	ginkgo.It("configure with json", func() {
+		filename := writeConfig("lork.json", `{
+  "writers": [{"name": "CONSOLE", "type": "console", "filter": {"type": "keyword", "keywords": ["secret"]}}],
+  "loggers": [{"name": "github.com/acme/http", "level": "error", "writers": ["CONSOLE"]}]
+}`)
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		Expect(fc.Configure(ctx)).To(Equal(StatusNext))
+		Expect(ctx.RealLogger("github.com/acme/http/client").EffectiveLevel()).To(Equal(ErrorLevel))
+		Expect(ctx.RealLogger("github.com/acme/http").GetWriter("CONSOLE")).NotTo(BeNil())
+	})
+	ginkgo.It("invalid config", func() {
+		filename := writeConfig("lork.yml", `
+writers:
+  - name: FILE
+    type: file
+  - name: ENCODER
+    type: file
+    filename: lork.log
+    encoder:
+      type: pattern
+      pattern: "#level #unknown"
+  - name: POLICY
+    type: file
+    filename: lork.log
+    rolling_policy:
+      type: time_based
+      filename_pattern: lork.#date{2006-01-02}.#index.log
+  - name: SOCKET
+    type: socket
+    remote_url: http://localhost
+  - name: DELAY
+    type: socket
+    remote_url: ws://localhost
+    reconnection_delay: soon
+  - name: UNUSED
+    type: unknown
+loggers:
+  - name: ROOT
+    level: verbose
+    writers: [FILE, ENCODER, POLICY, SOCKET, DELAY, MISSING]
+  - name: github.com/acme/db
+    writers: [FILE]
+`)
+		_, err := NewFileConfigurator(filename)
+		var ce *ConfigError
+		Expect(errors.As(err, &ce)).To(BeTrue())
+		Expect(ce.Problems).To(Equal([]string{
+			`loggers[0] (ROOT): invalid level "verbose"`,
+			`writers[0] (FILE): missing filename`,
+			`writers[1] (ENCODER): encoder: compile pattern error, failed to resolve converter for [unknown]`,
+			`writers[2] (POLICY): rolling_policy: filename_pattern "lork.#date{2006-01-02}.#index.log" should not contain #index`,
+			`writers[3] (SOCKET): invalid remote_url "http://localhost"`,
+			`writers[4] (DELAY): invalid reconnection_delay "soon"`,
+			`loggers[0] (ROOT): no such writer [MISSING]`,
+			`loggers[1] (github.com/acme/db): writer [FILE] is already referenced by loggers[0] (ROOT)`,
+			`writers[5] (UNUSED): not referenced by any logger or writer`,
+		}))
+	})
+	ginkgo.It("unknown field and format", func() {
+		_, err := NewFileConfigurator(writeConfig("lork.json", `{"writer": []}`))
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "writer"`)))
+
+		_, err = NewFileConfigurator(writeConfig("lork.toml", ``))
+		Expect(err).To(MatchError(ContainSubstring(`unsupported config format ".toml"`)))
+	})
+})
+
+var _ = ginkgo.Describe("file configurator reload", func() {
+	var dir, filename string
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "lork-reload")
+		Expect(err).To(BeNil())
+		filename = filepath.Join(dir, "lork.yaml")
+	})
+	ginkgo.AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	writeConfig := func(logfile, level string) {
+		Expect(os.WriteFile(filename, []byte(`
+writers:
+  - name: ASYNC
+    type: async
+    queue_size: 4096
+    writers: [FILE]
+  - name: FILE
+    type: file
+    filename: `+filepath.Join(dir, logfile)+`
+    encoder:
+      type: pattern
+      pattern: "#message"
+loggers:
+  - name: ROOT
+    writers: [ASYNC]
+  - name: github.com/acme/db
+    level: `+level+`
+`), 0644)).To(BeNil())
+	}
+	countLines := func(names ...string) int {
+		var count int
+		for _, name := range names {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			Expect(err).To(BeNil())
+			count += strings.Count(string(data), "\n")
+		}
+		return count
+	}
+
+	ginkgo.It("swap writers without dropping events", func() {
+		writeConfig("a.log", "debug")
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		Expect(fc.Configure(ctx)).To(Equal(StatusNoNext))
+
+		const total = 2000
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			logger := ctx.Logger("github.com/acme/http")
+			for i := 0; i < total; i++ {
+				logger.Info().Int("i", i).Msg("hello")
+			}
+		}()
+
+		writeConfig("b.log", "warn")
+		Expect(fc.Reload()).To(BeNil())
+		<-done
+		Expect(ctx.Shutdown(context.Background())).To(BeNil())
+
+		Expect(countLines("a.log", "b.log")).To(Equal(total))
+		Expect(ctx.RealLogger("github.com/acme/db").EffectiveLevel()).To(Equal(WarnLevel))
+	})
+	ginkgo.It("keep levels in environment after reload", func() {
+		Expect(os.Setenv(LevelEnvKey, "github.com/acme/db=error")).To(BeNil())
+		defer os.Unsetenv(LevelEnvKey)
+
+		writeConfig("a.log", "debug")
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		Expect((&LevelConfigurator{}).Configure(ctx)).To(Equal(StatusNext))
+		fc.Configure(ctx)
+		Expect(ctx.RealLogger("github.com/acme/db").EffectiveLevel()).To(Equal(ErrorLevel))
+
+		writeConfig("b.log", "warn")
+		Expect(fc.Reload()).To(BeNil())
+		Expect(ctx.RealLogger("github.com/acme/db").EffectiveLevel()).To(Equal(ErrorLevel))
+	})
+	ginkgo.It("keep current config if invalid", func() {
+		writeConfig("a.log", "debug")
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		fc.Configure(ctx)
+
+		writeConfig("b.log", "verbose")
+		var ce *ConfigError
+		Expect(errors.As(fc.Reload(), &ce)).To(BeTrue())
+		Expect(ctx.RealLogger(RootLoggerName).GetWriter("ASYNC")).NotTo(BeNil())
+		Expect(ctx.RealLogger("github.com/acme/db").EffectiveLevel()).To(Equal(DebugLevel))
+	})
+	ginkgo.It("keep current config if writer fails to start", func() {
+		writeConfig("a.log", "debug")
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		fc.Configure(ctx)
+
+		// the directory of logfile is not writable since it's a regular file
+		Expect(os.WriteFile(filepath.Join(dir, "readonly"), nil, 0444)).To(BeNil())
+		writeConfig(filepath.Join("readonly", "b.log"), "warn")
+		Expect(fc.Reload()).NotTo(BeNil())
+		Expect(ctx.RealLogger("github.com/acme/db").EffectiveLevel()).To(Equal(DebugLevel))
+
+		ctx.Logger("github.com/acme/http").Info().Msg("hello")
+		Expect(ctx.Shutdown(context.Background())).To(BeNil())
+		Expect(countLines("a.log")).To(Equal(1))
+	})
+	ginkgo.It("reload once changed", func() {
+		writeConfig("a.log", "debug")
+		fc, err := NewFileConfigurator(filename, func(o *FileConfiguratorOption) {
+			o.ScanPeriod = 10 * time.Millisecond
+		})
+		Expect(err).To(BeNil())
+		ctx := NewLoggerContext(NewClassicLogger)
+		defer ctx.ResetWriter()
+		fc.Configure(ctx)
+		defer fc.Stop()
+
+		writeConfig("b.log", "error")
+		Eventually(func() Level {
+			return ctx.RealLogger("github.com/acme/db").EffectiveLevel()
+		}).Should(Equal(ErrorLevel))
+	})
+})
@@ -15,6 +15,8 @@
 package lork
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,9 +28,10 @@ const defaultLogFilename = "lork.log"
 type fileWriter struct {
 	opts *FileWriterOption
 
-	locker sync.Locker
-	file   *os.File
-	size   int64
+	locker  sync.Locker
+	file    *os.File
+	size    int64
+	lastErr error
 }
 
 // FileWriterOption represents available options for file writer.
@@ -63,24 +66,103 @@ func NewFileWriter(options ...func(*FileWriterOption)) Writer {
 	return NewBytesWriter(fw)
 }
 
+func init() {
+	RegisterWriter("file", newFileWriterFromConfig)
+}
+
+type fileWriterConfig struct {
+	Filename      string          `json:"filename"`
+	Encoder       ComponentConfig `json:"encoder"`
+	Filter        ComponentConfig `json:"filter"`
+	RollingPolicy ComponentConfig `json:"rolling_policy"`
+}
+
+func newFileWriterFromConfig(d OptionDecoder) (Writer, error) {
+	var c fileWriterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if len(c.Filename) == 0 {
+		return nil, errors.New("missing filename")
+	}
+	encoder, err := d.Encoder(c.Encoder)
+	if err != nil {
+		return nil, fmt.Errorf("encoder: %w", err)
+	}
+	filter, err := d.Filter(c.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+	policy, err := d.RollingPolicy(c.RollingPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("rolling_policy: %w", err)
+	}
+
+	return NewFileWriter(func(o *FileWriterOption) {
+		o.Name = d.Name()
+		o.Filename = c.Filename
+		if encoder != nil {
+			o.Encoder = encoder
+		}
+		o.Filter = filter
+		o.RollingPolicy = policy
+	}), nil
+}
+
 func (fw *fileWriter) Start() {
-	if err := fw.openExistingOrNew(); err != nil {
+	if err := fw.StartChecked(); err != nil {
 		ReportfExit("file writer start error: %v", err)
 	}
+}
+
+// StartChecked opens the logfile and prepares the rolling policy, the logfile will
+// be closed if the rolling policy fails to prepare.
+func (fw *fileWriter) StartChecked() error {
+	if err := fw.openExistingOrNew(); err != nil {
+		return err
+	}
 
 	if err := fw.opts.RollingPolicy.Prepare(); err != nil {
-		ReportfExit("start rolling policy error: %v\n", err)
+		_ = fw.Close()
+		return fmt.Errorf("start rolling policy error: %v", err)
 	}
+
+	return nil
 }
 
 func (fw *fileWriter) Stop() {
 	_ = fw.Close()
 }
 
-func (fw *fileWriter) Write(p []byte) (n int, err error) {
+// Flush commits the current logfile to storage and waits for in-flight archiving.
+func (fw *fileWriter) Flush(ctx context.Context) error {
+	fw.locker.Lock()
+	if fw.file != nil {
+		if err := fw.file.Sync(); err != nil {
+			fw.locker.Unlock()
+			return err
+		}
+	}
+	fw.locker.Unlock()
+
+	return flush(ctx, fw.opts.RollingPolicy)
+}
+
+// Health reports the error of last writing if it failed.
+func (fw *fileWriter) Health() error {
 	fw.locker.Lock()
 	defer fw.locker.Unlock()
 
+	return fw.lastErr
+}
+
+func (fw *fileWriter) Write(p []byte) (n int, err error) {
+	fw.locker.Lock()
+	defer func() {
+		fw.lastErr = err
+		fw.locker.Unlock()
+	}()
+
 	writeLen := len(p)
 	if fw.file == nil {
 		if err = fw.openExistingOrNew(); err != nil {
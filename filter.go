@@ -16,7 +16,12 @@ package lork
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
 )
 
 // FilterReply defines the result of filter.
@@ -35,6 +40,17 @@ type Filter interface {
 	Do(e *LogEvent) FilterReply
 }
 
+// EmittingFilter represents a stateful filter which emits extra events, such as
+// summary, through the writer it guards.
+type EmittingFilter interface {
+	Filter
+
+	// Attach attaches the function to write events into the guarded writer directly.
+	// The emit function can be called in any goroutine, but not in Do which is called
+	// when the writer is writing.
+	Attach(emit func(e *LogEvent) error)
+}
+
 // thresholdFilter represents a filter with threshold.
 type thresholdFilter struct {
 	level Level
@@ -47,6 +63,29 @@ func NewThresholdFilter(lvl Level) Filter {
 	}
 }
 
+func init() {
+	RegisterFilter("threshold", newThresholdFilterFromConfig)
+	RegisterFilter("keyword", newKeywordFilterFromConfig)
+	RegisterFilter("duplicate", newDuplicateFilterFromConfig)
+}
+
+type thresholdFilterConfig struct {
+	Level string `json:"level"`
+}
+
+func newThresholdFilterFromConfig(d OptionDecoder) (Filter, error) {
+	var c thresholdFilterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	lvl, ok := LookupLevel(c.Level)
+	if !ok {
+		return nil, fmt.Errorf("invalid level %q", c.Level)
+	}
+
+	return NewThresholdFilter(lvl), nil
+}
+
 func (f *thresholdFilter) Do(e *LogEvent) FilterReply {
 	if e.LevelInt() >= f.level {
 		return Accept
@@ -55,7 +94,8 @@ func (f *thresholdFilter) Do(e *LogEvent) FilterReply {
 	return Deny
 }
 
-// keywordFilter represents a filter by key word rule.
+// keywordFilter represents a filter by key word rule. The keyword can be key, value
+// or key=value, fields in nested object are matched with dotted path such as a.b=c.
 type keywordFilter struct {
 	keywords []string
 }
@@ -67,13 +107,49 @@ func NewKeywordFilter(keywords ...string) Filter {
 	}
 }
 
+type keywordFilterConfig struct {
+	Keywords []string `json:"keywords"`
+}
+
+func newKeywordFilterFromConfig(d OptionDecoder) (Filter, error) {
+	var c keywordFilterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if len(c.Keywords) == 0 {
+		return nil, errors.New("keyword filter needs keywords")
+	}
+
+	return NewKeywordFilter(c.Keywords...), nil
+}
+
 var errFound = errors.New("found")
 
 func (f *keywordFilter) Do(e *LogEvent) FilterReply {
-	err := e.Fields(func(k, v []byte, _ bool) error {
+	// string values are stored escaped, keywords are matched with the raw ones
+	var scratch [64]byte
+	err := e.WalkFields(func(k, v []byte, isString bool) error {
+		if isString {
+			v = unescape(v, scratch[:0])
+		}
 		if f.compare(k, v) {
 			return errFound
 		}
+		if isString || len(v) == 0 || v[0] != '[' {
+			return nil
+		}
+
+		// match each element in array
+		var found bool
+		_, _ = jsonparser.ArrayEach(v, func(ev []byte, typ jsonparser.ValueType, _ int, _ error) {
+			if typ == jsonparser.String {
+				ev = unescape(ev, scratch[:0])
+			}
+			found = found || f.compare(k, ev)
+		})
+		if found {
+			return errFound
+		}
 
 		return nil
 	})
@@ -99,3 +175,225 @@ func (f *keywordFilter) compare(key []byte, value []byte) bool {
 
 	return false
 }
+
+const duplicateRepeatedFieldKey = "repeated"
+
+// duplicateSeparator separates the parts of event when hashing.
+var duplicateSeparator = []byte{0}
+
+// DuplicateFilterOption represents available options for duplicate filter.
+type DuplicateFilterOption struct {
+	// Window is the time window to suppress duplicate messages, default is 10 seconds.
+	Window time.Duration
+	// Clock returns current time, default is time.Now.
+	Clock func() time.Time
+}
+
+type duplicateEntry struct {
+	expireAt   int64
+	repeated   uint64
+	level      Level
+	loggerName string
+	message    string
+}
+
+func (entry *duplicateEntry) matches(e *LogEvent) bool {
+	return entry.level == e.LevelInt() && entry.loggerName == string(e.LoggerName()) &&
+		entry.message == string(e.Message())
+}
+
+// duplicateFilter represents a filter which suppresses the duplicate messages with
+// same logger name, level and message in a time window.
+type duplicateFilter struct {
+	locker  sync.Mutex
+	window  int64
+	period  time.Duration
+	clock   func() time.Time
+	entries map[uint64][]*duplicateEntry
+	pending []*duplicateEntry
+	emit    func(e *LogEvent) error
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewDuplicateFilter creates a new instance of duplicateFilter. The first message
+// will be accepted, and the same messages seen in the window will be denied. When the
+// window closes, a summary with repeated times will be emitted through the writer.
+func NewDuplicateFilter(options ...func(*DuplicateFilterOption)) Filter {
+	opts := &DuplicateFilterOption{
+		Window: 10 * time.Second,
+		Clock:  time.Now,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	return &duplicateFilter{
+		window:  opts.Window.Nanoseconds(),
+		period:  opts.Window,
+		clock:   opts.Clock,
+		entries: make(map[uint64][]*duplicateEntry),
+	}
+}
+
+type duplicateFilterConfig struct {
+	Window string `json:"window"`
+}
+
+func newDuplicateFilterFromConfig(d OptionDecoder) (Filter, error) {
+	var c duplicateFilterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	window, err := parseDuration("window", c.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDuplicateFilter(func(o *DuplicateFilterOption) {
+		if window > 0 {
+			o.Window = window
+		}
+	}), nil
+}
+
+func (f *duplicateFilter) Attach(emit func(e *LogEvent) error) {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	f.emit = emit
+}
+
+// Start starts to emit the summary of expired messages periodically.
+func (f *duplicateFilter) Start() {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	if f.stop != nil || f.period <= 0 {
+		return
+	}
+	f.stop = make(chan struct{})
+	f.done = make(chan struct{})
+	go f.run(f.stop, f.done)
+}
+
+// Stop emits the summary of all messages repeated.
+func (f *duplicateFilter) Stop() {
+	f.locker.Lock()
+	stop, done := f.stop, f.done
+	f.stop, f.done = nil, nil
+	f.locker.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	f.flush(true)
+}
+
+func (f *duplicateFilter) Do(e *LogEvent) FilterReply {
+	now := f.clock().UnixNano()
+	key := fnv64a(fnv64Offset, e.LoggerName())
+	key = fnv64a(fnv64a(key, duplicateSeparator), e.Level())
+	key = fnv64a(fnv64a(key, duplicateSeparator), e.Message())
+
+	f.locker.Lock()
+	defer f.locker.Unlock()
+
+	entries := f.entries[key]
+	for i, entry := range entries {
+		if !entry.matches(e) {
+			continue
+		}
+		if entry.expireAt > now {
+			entry.repeated++
+			return Deny
+		}
+
+		// the window closed but summary not emitted yet
+		if entry.repeated > 0 {
+			f.pending = append(f.pending, entry)
+		}
+		entries[i] = f.newEntry(e, now)
+		return Accept
+	}
+	f.entries[key] = append(entries, f.newEntry(e, now))
+
+	return Accept
+}
+
+func (f *duplicateFilter) newEntry(e *LogEvent, now int64) *duplicateEntry {
+	return &duplicateEntry{
+		expireAt:   now + f.window,
+		level:      e.LevelInt(),
+		loggerName: string(e.LoggerName()),
+		message:    string(e.Message()),
+	}
+}
+
+func (f *duplicateFilter) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(f.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			f.flush(false)
+		}
+	}
+}
+
+// flush removes the expired entries and emits summary for repeated messages, all
+// entries will be removed if force is true. The summary is emitted without holding
+// the lock, cause emit may wait for the writer which is calling Do.
+func (f *duplicateFilter) flush(force bool) {
+	now := f.clock().UnixNano()
+
+	f.locker.Lock()
+	expired := f.pending
+	f.pending = nil
+	for key, entries := range f.entries {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if !force && entry.expireAt > now {
+				kept = append(kept, entry)
+				continue
+			}
+			if entry.repeated > 0 {
+				expired = append(expired, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(f.entries, key)
+		} else {
+			f.entries[key] = kept
+		}
+	}
+	emit := f.emit
+	f.locker.Unlock()
+
+	if emit == nil {
+		return
+	}
+	for _, entry := range expired {
+		f.emitSummary(emit, entry, now)
+	}
+}
+
+func (f *duplicateFilter) emitSummary(emit func(e *LogEvent) error,
+	entry *duplicateEntry, now int64) {
+	e := NewLogEvent()
+	e.unixNano = now
+	e.appendLevel(entry.level)
+	e.loggerName.WriteString(entry.loggerName)
+	e.appendMessage(fmt.Sprintf("message repeated %d times: %s", entry.repeated,
+		entry.message))
+	e.appendUint(duplicateRepeatedFieldKey, entry.repeated)
+	if err := emit(e); err != nil {
+		Reportf("emit duplicate summary error: %v", err)
+	}
+	e.Recycle()
+}
@@ -15,6 +15,9 @@
 package lork
 
 import (
+	"sync"
+	"time"
+
 	"github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -45,3 +48,146 @@ var _ = ginkgo.Describe("keyword filter", func() {
 		Expect(result).To(Equal(Accept))
 	})
 })
+var _ = ginkgo.Describe("keyword filter with nested fields", func() {
+	var event = MakeEvent([]byte(`{"level":"INFO",` +
+		`"user":{"name":"lork","tags":["a","b"]},"ids":[1,2]}`))
+	ginkgo.It("not filter", func() {
+		filter := NewKeywordFilter("name=lork", "user=lork", "user.tags=c")
+		result := filter.Do(event)
+		Expect(result).To(Equal(Deny))
+	})
+	ginkgo.It("filter dotted path", func() {
+		Expect(NewKeywordFilter("user.name=lork").Do(event)).To(Equal(Accept))
+		Expect(NewKeywordFilter("user.name").Do(event)).To(Equal(Accept))
+		Expect(NewKeywordFilter("lork").Do(event)).To(Equal(Accept))
+	})
+	ginkgo.It("filter array element", func() {
+		Expect(NewKeywordFilter("user.tags=b").Do(event)).To(Equal(Accept))
+		Expect(NewKeywordFilter("ids=2").Do(event)).To(Equal(Accept))
+	})
+})
+var _ = ginkgo.Describe("keyword filter with escaped strings", func() {
+	var event = MakeEvent([]byte(`{"level":"INFO","path":"C:\\dir",` +
+		`"quote":"say \"hi\"","tags":["a\\b"]}`))
+	ginkgo.It("filter", func() {
+		Expect(NewKeywordFilter(`path=C:\dir`).Do(event)).To(Equal(Accept))
+		Expect(NewKeywordFilter(`quote=say "hi"`).Do(event)).To(Equal(Accept))
+		Expect(NewKeywordFilter(`say "hi"`).Do(event)).To(Equal(Accept))
+		Expect(NewKeywordFilter(`tags=a\b`).Do(event)).To(Equal(Accept))
+	})
+	ginkgo.It("not filter", func() {
+		Expect(NewKeywordFilter(`path=C:\\dir`).Do(event)).To(Equal(Deny))
+		Expect(NewKeywordFilter(`quote=say \"hi\"`).Do(event)).To(Equal(Deny))
+	})
+})
+var _ = ginkgo.Describe("duplicate filter", func() {
+	ginkgo.It("suppress and summarize", func() {
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		filter := NewDuplicateFilter(func(o *DuplicateFilterOption) {
+			o.Window = time.Second
+			o.Clock = func() time.Time {
+				return now
+			}
+		})
+		ew := &filteredEventWriter{filter: filter}
+		writer := NewEventWriter(ew)
+		writer.(Lifecycle).Start()
+		write := func(name, level, msg string) {
+			event := MakeEvent([]byte(`{"logger_name":"` + name + `","level":"` + level +
+				`","message":"` + msg + `"}`))
+			Expect(writer.DoWrite(event)).To(BeNil())
+			event.Recycle()
+		}
+
+		write("a", "INFO", "hello")
+		write("a", "INFO", "hello")
+		write("a", "INFO", "hello")
+		write("b", "INFO", "hello")
+		write("a", "WARN", "hello")
+		write("a", "INFO", "other")
+		Expect(ew.messages()).To(Equal([]string{"hello", "hello", "hello", "other"}))
+
+		now = now.Add(time.Second)
+		write("a", "INFO", "hello")
+		Expect(ew.events).To(HaveLen(5))
+		Expect(string(ew.events[4].Message())).To(Equal("hello"))
+
+		filter.(*duplicateFilter).flush(false)
+		Expect(ew.events).To(HaveLen(6))
+		summary := ew.events[5]
+		Expect(string(summary.Message())).To(Equal("message repeated 2 times: hello"))
+		Expect(string(summary.LoggerName())).To(Equal("a"))
+		Expect(summary.LevelInt()).To(Equal(InfoLevel))
+		Expect(summary.Timestamp()).To(Equal(now.UnixNano()))
+		Expect(collectFields(summary)).To(Equal("repeated=2 "))
+
+		write("a", "INFO", "hello")
+		writer.(Lifecycle).Stop()
+		Expect(ew.events).To(HaveLen(7))
+		Expect(string(ew.events[6].Message())).To(Equal("message repeated 1 times: hello"))
+	})
+	ginkgo.It("compare parts of event", func() {
+		ew := &filteredEventWriter{filter: NewDuplicateFilter()}
+		writer := NewEventWriter(ew)
+		writer.(Lifecycle).Start()
+		defer writer.(Lifecycle).Stop()
+
+		Expect(writer.DoWrite(MakeEvent([]byte(
+			`{"logger_name":"x","level":"INFO","message":"INFOy"}`)))).To(BeNil())
+		Expect(writer.DoWrite(MakeEvent([]byte(
+			`{"logger_name":"xINFO","level":"INFO","message":"y"}`)))).To(BeNil())
+		Expect(ew.messages()).To(Equal([]string{"INFOy", "y"}))
+	})
+	ginkgo.It("summarize when window closes", func() {
+		ew := &filteredEventWriter{filter: NewDuplicateFilter(func(o *DuplicateFilterOption) {
+			o.Window = 20 * time.Millisecond
+		})}
+		writer := NewEventWriter(ew)
+		writer.(Lifecycle).Start()
+		defer writer.(Lifecycle).Stop()
+
+		for i := 0; i < 3; i++ {
+			Expect(writer.DoWrite(MakeEvent([]byte(
+				`{"level":"INFO","message":"hello"}`)))).To(BeNil())
+		}
+		Eventually(ew.messages).Should(Equal([]string{"hello",
+			"message repeated 2 times: hello"}))
+	})
+})
+
+type filteredEventWriter struct {
+	locker sync.Mutex
+	filter Filter
+	events []*LogEvent
+}
+
+func (w *filteredEventWriter) Name() string {
+	return "FILTERED"
+}
+
+func (w *filteredEventWriter) Write(event *LogEvent) error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	w.events = append(w.events, event.Copy())
+	return nil
+}
+
+func (w *filteredEventWriter) Filter() Filter {
+	return w.filter
+}
+
+func (w *filteredEventWriter) Synchronized() bool {
+	return true
+}
+
+func (w *filteredEventWriter) messages() []string {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	var messages []string
+	for _, e := range w.events {
+		messages = append(messages, string(e.Message()))
+	}
+	return messages
+}
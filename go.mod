@@ -7,4 +7,5 @@ require (
 	github.com/gorilla/websocket v1.5.0
 	github.com/onsi/ginkgo/v2 v2.1.6
 	github.com/onsi/gomega v1.20.2
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/buger/jsonparser"
@@ -43,6 +44,13 @@ func ReportfExit(format string, args ...interface{}) {
 	os.Exit(0)
 }
 
+// setIfNotEmpty sets the value to dst if it's not empty.
+func setIfNotEmpty(dst *string, value string) {
+	if len(value) != 0 {
+		*dst = value
+	}
+}
+
 // colorize adds ANSI color for given string.
 func colorize(color int, s string) string {
 	return fmt.Sprintf("\x1b[%dm%v\x1b[0m", color, s)
@@ -194,8 +202,29 @@ func PackageName(skip int) string {
 	return fn[:index]
 }
 
-// BridgeWrite writes data from bridge to lork logger.
+// CallerFrame gets the file with line and the function name of caller. The argument
+// skip is the number of stack frames to ascend, with 0 identifying the caller of
+// CallerFrame.
+func CallerFrame(skip int) (caller, function string, ok bool) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "", "", false
+	}
+
+	caller = file + ":" + strconv.Itoa(line)
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		function = fn.Name()
+	}
+
+	return caller, function, true
+}
+
+// BridgeWrite writes data from bridge to lork logger. The timestamp will be kept, and
+// the name of bridge will be used as logger name unless the bridge is a RoutingBridge
+// which routes by logger name and the logger name is found in data.
 func BridgeWrite(bridge Bridge, p []byte) {
+	rb, ok := bridge.(RoutingBridge)
+	routing := ok && rb.RouteByLoggerName()
 	event := NewLogEvent()
 	_ = jsonparser.ObjectEach(p, func(key []byte, value []byte,
 		dataType jsonparser.ValueType, _ int) error {
@@ -204,8 +233,20 @@ func BridgeWrite(bridge Bridge, p []byte) {
 			event.appendLevel(bridge.ParseLevel(string(value)))
 		case MessageFieldKey:
 			event.appendMessageBytes(value)
+		case LoggerNameFieldKey:
+			if routing {
+				event.appendLogger(value)
+			} else {
+				event.makeFields(key, value, dataType == jsonparser.String)
+			}
+		case CallerFieldKey:
+			event.appendCallerBytes(value)
+		case FunctionFieldKey:
+			event.appendCallerFuncBytes(value)
+		case StackFieldKey:
+			event.appendStackBytes(value)
 		case TimestampFieldKey:
-			// Do nothing
+			event.appendRFC3999Nano(value)
 		default:
 			event.makeFields(key, value, dataType == jsonparser.String)
 		}
@@ -213,8 +254,44 @@ func BridgeWrite(bridge Bridge, p []byte) {
 		return nil
 	})
 
+	if event.loggerName.Len() != 0 {
+		Logger(string(event.LoggerName())).Event(event)
+		return
+	}
+
 	// add bridge name as logger name
 	event.appendLogger([]byte(bridge.Name()))
-
 	Logger(bridge.Name()).Event(event)
 }
+
+// fnv32a hashes bytes with FNV-1a.
+func fnv32a(p []byte) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+
+	hash := uint32(offset32)
+	for _, c := range p {
+		hash ^= uint32(c)
+		hash *= prime32
+	}
+
+	return hash
+}
+
+const (
+	fnv64Offset = 14695981039346656037
+	fnv64Prime  = 1099511628211
+)
+
+// fnv64a hashes bytes with FNV-1a based on given hash, which makes it possible to
+// hash multiple byte slices without joining them.
+func fnv64a(hash uint64, p []byte) uint64 {
+	for _, c := range p {
+		hash ^= uint64(c)
+		hash *= fnv64Prime
+	}
+
+	return hash
+}
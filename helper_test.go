@@ -16,6 +16,8 @@ package lork
 
 import (
 	"bytes"
+	"time"
+
 	"github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -32,3 +34,74 @@ var _ = ginkgo.Describe("helper", func() {
 		Expect(buf.String()).To(Equal(result))
 	})
 })
+
+type testBridge struct {
+	name string
+}
+
+func (b *testBridge) Name() string {
+	return b.name
+}
+
+func (b *testBridge) ParseLevel(lvl string) Level {
+	level, _ := LookupLevel(lvl)
+	return level
+}
+
+type testRoutingBridge struct {
+	testBridge
+}
+
+func (b *testRoutingBridge) RouteByLoggerName() bool {
+	return true
+}
+
+var _ = ginkgo.Describe("bridge write", func() {
+	var collector *eventCollector
+
+	ginkgo.BeforeEach(func() {
+		Reset()
+		collector = &eventCollector{}
+		// sync writer appends the timestamp when writing if not set
+		Manual().AddWriter(NewSyncWriter(collector))
+	})
+	ginkgo.AfterEach(func() {
+		Reset()
+	})
+
+	ginkgo.It("route by logger name", func() {
+		BridgeWrite(&testRoutingBridge{testBridge{name: "test/routing"}},
+			[]byte(`{"level":"WARN","logger_name":"github.com/acme/db","message":"hello"}`))
+		event := collector.last()
+		Expect(string(event.LoggerName())).To(Equal("github.com/acme/db"))
+		Expect(event.LevelInt()).To(Equal(WarnLevel))
+		Expect(string(event.Message())).To(Equal("hello"))
+		Expect(collectFields(event)).To(BeEmpty())
+	})
+	ginkgo.It("write to logger named by bridge", func() {
+		BridgeWrite(&testBridge{name: "test/bridge"},
+			[]byte(`{"level":"WARN","logger_name":"github.com/acme/db","message":"hello"}`))
+		event := collector.last()
+		Expect(string(event.LoggerName())).To(Equal("test/bridge"))
+		Expect(collectFields(event)).To(Equal("logger_name=github.com/acme/db "))
+	})
+	ginkgo.It("keep timestamp", func() {
+		BridgeWrite(&testBridge{name: "test/bridge"},
+			[]byte(`{"level":"INFO","time":"2023-01-02T15:04:05+08:00","message":"hello"}`))
+		expected := time.Date(2023, 1, 2, 7, 4, 5, 0, time.UTC).UnixNano()
+		Expect(collector.last().Timestamp()).To(Equal(expected))
+
+		BridgeWrite(&testBridge{name: "test/bridge"},
+			[]byte(`{"level":"INFO","time":"2023-01-02T15:04:05.123456789Z","message":"hello"}`))
+		expected = time.Date(2023, 1, 2, 15, 4, 5, 123456789, time.UTC).UnixNano()
+		Expect(collector.last().Timestamp()).To(Equal(expected))
+	})
+	ginkgo.It("use write time if timestamp is invalid", func() {
+		before := time.Now().UnixNano()
+		BridgeWrite(&testBridge{name: "test/bridge"},
+			[]byte(`{"level":"INFO","time":"yesterday","message":"hello"}`))
+		after := time.Now().UnixNano()
+		Expect(collector.last().Timestamp()).To(BeNumerically(">=", before))
+		Expect(collector.last().Timestamp()).To(BeNumerically("<=", after))
+	})
+})
@@ -0,0 +1,173 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook represents a hook which can inspect and mutate LogEvent after the record is
+// built but before it's written into writers. Hooks added into LoggerContext run
+// first in the order they were added, then the hooks of named loggers run from the
+// top ancestor to the logger itself. The sampler of each logger runs after its hooks.
+// Hooks may be called concurrently.
+type Hook interface {
+	// Fire is called with the event before writing, the event will be dropped
+	// if false returned.
+	Fire(e *LogEvent) bool
+}
+
+// HookFunc is a function to implement Hook.
+type HookFunc func(e *LogEvent) bool
+
+// Fire calls the function itself.
+func (f HookFunc) Fire(e *LogEvent) bool {
+	return f(e)
+}
+
+// HookAttachable is interface definition for attaching hooks to objects.
+type HookAttachable interface {
+	// AddHook adds one or more hook.
+	AddHook(hooks ...Hook)
+}
+
+// hookSet is a snapshot of registered hooks.
+type hookSet struct {
+	global   []Hook
+	named    map[string][]Hook
+	samplers map[string]Hook
+}
+
+// hookChain holds the global hooks and hooks of named loggers, the hooks are stored
+// with copy-on-write to keep firing lock free.
+type hookChain struct {
+	locker sync.Mutex
+	hooks  atomic.Value
+}
+
+func newHookChain() *hookChain {
+	hc := &hookChain{}
+	hc.hooks.Store(&hookSet{})
+
+	return hc
+}
+
+// add adds hooks for the logger with given name, or global hooks if name is empty.
+func (hc *hookChain) add(name string, hooks ...Hook) {
+	hc.locker.Lock()
+	defer hc.locker.Unlock()
+
+	old := hc.hooks.Load().(*hookSet)
+	set := old.copy()
+	if len(name) == 0 {
+		set.global = append(append([]Hook(nil), old.global...), hooks...)
+	} else {
+		set.named[name] = append(append([]Hook(nil), old.named[name]...), hooks...)
+	}
+	hc.hooks.Store(set)
+}
+
+// setSampler sets the sampling hook for the logger with given name, or global
+// sampling hook if name is empty. The sampling hook will be removed if it's nil.
+// The new sampling hook will be started, and the replaced one will be stopped.
+func (hc *hookChain) setSampler(name string, sampler Hook) {
+	if lc, ok := sampler.(Lifecycle); ok {
+		lc.Start()
+	}
+
+	hc.locker.Lock()
+	set := hc.hooks.Load().(*hookSet).copy()
+	old := set.samplers[name]
+	if sampler == nil {
+		delete(set.samplers, name)
+	} else {
+		set.samplers[name] = sampler
+	}
+	hc.hooks.Store(set)
+	hc.locker.Unlock()
+
+	if lc, ok := old.(Lifecycle); ok {
+		lc.Stop()
+	}
+}
+
+// stopSamplers stops all the sampling hooks, the summary of dropped events will be
+// written. The hooks are kept sampling events.
+func (hc *hookChain) stopSamplers() {
+	set := hc.hooks.Load().(*hookSet)
+	for _, sampler := range set.samplers {
+		if lc, ok := sampler.(Lifecycle); ok {
+			lc.Stop()
+		}
+	}
+}
+
+// fire fires all the hooks matched with the logger name of event. It returns false if
+// any hook wants to drop the event.
+func (hc *hookChain) fire(e *LogEvent) bool {
+	set := hc.hooks.Load().(*hookSet)
+	if !set.fire(e, set.global, set.samplers[""]) {
+		return false
+	}
+
+	if len(set.named) == 0 && len(set.samplers) == 0 {
+		return true
+	}
+
+	name := e.LoggerName()
+	for i := 0; i <= len(name); {
+		index := bytes.IndexByte(name[i:], '/')
+		end := len(name)
+		if index >= 0 {
+			end = i + index
+		}
+		prefix := name[:end]
+		if !set.fire(e, set.named[string(prefix)], set.samplers[string(prefix)]) {
+			return false
+		}
+		i = end + 1
+	}
+
+	return true
+}
+
+func (s *hookSet) copy() *hookSet {
+	cp := &hookSet{
+		global:   s.global,
+		named:    make(map[string][]Hook, len(s.named)+1),
+		samplers: make(map[string]Hook, len(s.samplers)+1),
+	}
+	for k, v := range s.named {
+		cp.named[k] = v
+	}
+	for k, v := range s.samplers {
+		cp.samplers[k] = v
+	}
+
+	return cp
+}
+
+// fire fires the hooks and then the sampler.
+func (s *hookSet) fire(e *LogEvent, hooks []Hook, sampler Hook) bool {
+	for _, h := range hooks {
+		if !h.Fire(e) {
+			return false
+		}
+	}
+
+	return sampler == nil || sampler.Fire(e)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("hook", func() {
+	ginkgo.It("fire in order", func() {
+		ctx, collector := newCollectorContext()
+		var order []string
+		record := func(name string) Hook {
+			return HookFunc(func(e *LogEvent) bool {
+				order = append(order, name)
+				return true
+			})
+		}
+		ctx.RealLogger("a/b").AddHook(record("a/b"))
+		ctx.RealLogger("a").AddHook(record("a"))
+		ctx.RealLogger("ab").AddHook(record("ab"))
+		ctx.AddHook(record("global"))
+		ctx.RealLogger(RootLoggerName).AddHook(record("root"))
+
+		ctx.Logger("a/b/c").Info().Msg("hello")
+		Expect(order).To(Equal([]string{"global", "root", "a", "a/b"}))
+		Expect(collector.events).To(HaveLen(1))
+
+		order = order[:0]
+		ctx.Logger("ab").Info().Msg("hello")
+		Expect(order).To(Equal([]string{"global", "root", "ab"}))
+	})
+	ginkgo.It("mutate and drop", func() {
+		ctx, collector := newCollectorContext()
+		ctx.AddHook(HookFunc(func(e *LogEvent) bool {
+			e.AddField("host", "local")
+			e.RenameField("id", "request_id")
+			e.RemoveField("secret")
+			return true
+		}))
+		ctx.RealLogger("drop").AddHook(HookFunc(func(e *LogEvent) bool {
+			return string(e.Message()) != "dropped"
+		}))
+
+		ctx.Logger("keep").Info().Str("id", "abc").Str("secret", "s").Int("n", 1).Msg("kept")
+		Expect(collectFields(collector.last())).To(Equal(
+			"request_id=abc n=1 host=local "))
+
+		ctx.Logger("drop").Info().Msg("dropped")
+		Expect(collector.events).To(HaveLen(1))
+		ctx.Logger("drop").Info().Msg("written")
+		Expect(collector.events).To(HaveLen(2))
+	})
+	ginkgo.It("fire for raw event", func() {
+		ctx, collector := newCollectorContext()
+		ctx.AddHook(HookFunc(func(e *LogEvent) bool {
+			e.AddField("hooked", true)
+			return true
+		}))
+		ctx.Logger("raw").Event(MakeEvent([]byte(`{"level":"INFO","message":"raw"}`)))
+		Expect(collectFields(collector.last())).To(Equal("hooked=true "))
+	})
+})
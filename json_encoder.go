@@ -16,50 +16,180 @@ package lork
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"sync"
 )
 
+const (
+	// TimeFormatUnix formats timestamp as epoch seconds.
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMilli formats timestamp as epoch milliseconds.
+	TimeFormatUnixMilli = "unixmilli"
+	// TimeFormatUnixNano formats timestamp as epoch nanoseconds.
+	TimeFormatUnixNano = "unixnano"
+)
+
+// JsonEncoderOption represents available options for json encoder.
+type JsonEncoderOption struct {
+	TimeKey     string
+	LevelKey    string
+	LoggerKey   string
+	MessageKey  string
+	CallerKey   string
+	FunctionKey string
+	StackKey    string
+	// TimeFormat is the layout to format timestamp, or one of TimeFormatUnix,
+	// TimeFormatUnixMilli and TimeFormatUnixNano to format as epoch number.
+	// Default is TimeFormatRFC3339.
+	TimeFormat string
+	// LowercaseLevel encodes level in lowercase, such as info.
+	LowercaseLevel bool
+	// FieldsKey puts the fields of event under the nested key if not empty.
+	FieldsKey string
+	// Fields are the static fields added into each event.
+	Fields map[string]interface{}
+	// OmitEmptyMessage omits the message if it's empty.
+	OmitEmptyMessage bool
+	// OmitEmptyLogger omits the logger name if it's empty.
+	OmitEmptyLogger bool
+}
+
 // jsonEncoder encodes logging event into json format.
 type jsonEncoder struct {
+	opts   *JsonEncoderOption
+	fields []byte
 	locker sync.Mutex
 	buf    *bytes.Buffer
 	tsBuf  *bytes.Buffer
 }
 
 // NewJsonEncoder creates a new instance of encoder to encode data to json.
-func NewJsonEncoder() Encoder {
+func NewJsonEncoder(options ...func(*JsonEncoderOption)) Encoder {
+	je, err := newJsonEncoder(options...)
+	if err != nil {
+		ReportfExit("json encoder fields error: %v", err)
+	}
+
+	return je
+}
+
+// newJsonEncoder creates a json encoder, it returns error if the static fields
+// can not be marshalled.
+func newJsonEncoder(options ...func(*JsonEncoderOption)) (*jsonEncoder, error) {
+	opts := &JsonEncoderOption{
+		TimeKey:     TimestampFieldKey,
+		LevelKey:    LevelFieldKey,
+		LoggerKey:   LoggerNameFieldKey,
+		MessageKey:  MessageFieldKey,
+		CallerKey:   CallerFieldKey,
+		FunctionKey: FunctionFieldKey,
+		StackKey:    StackFieldKey,
+		TimeFormat:  TimeFormatRFC3339,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	// static fields are encoded once, such as "k1":"v1","k2":2,
+	var fields []byte
+	if len(opts.Fields) > 0 {
+		data, err := json.Marshal(opts.Fields)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(data[1:len(data)-1], ',')
+	}
+
 	return &jsonEncoder{
-		buf:   new(bytes.Buffer),
-		tsBuf: new(bytes.Buffer),
+		opts:   opts,
+		fields: fields,
+		buf:    new(bytes.Buffer),
+		tsBuf:  new(bytes.Buffer),
+	}, nil
+}
+
+func init() {
+	RegisterEncoder("json", newJsonEncoderFromConfig)
+}
+
+type jsonEncoderConfig struct {
+	TimeKey          string                 `json:"time_key"`
+	LevelKey         string                 `json:"level_key"`
+	LoggerKey        string                 `json:"logger_key"`
+	MessageKey       string                 `json:"message_key"`
+	CallerKey        string                 `json:"caller_key"`
+	FunctionKey      string                 `json:"function_key"`
+	StackKey         string                 `json:"stack_key"`
+	TimeFormat       string                 `json:"time_format"`
+	LowercaseLevel   bool                   `json:"lowercase_level"`
+	FieldsKey        string                 `json:"fields_key"`
+	Fields           map[string]interface{} `json:"fields"`
+	OmitEmptyMessage bool                   `json:"omit_empty_message"`
+	OmitEmptyLogger  bool                   `json:"omit_empty_logger"`
+}
+
+func newJsonEncoderFromConfig(d OptionDecoder) (Encoder, error) {
+	var c jsonEncoderConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
 	}
+
+	je, err := newJsonEncoder(func(o *JsonEncoderOption) {
+		setIfNotEmpty(&o.TimeKey, c.TimeKey)
+		setIfNotEmpty(&o.LevelKey, c.LevelKey)
+		setIfNotEmpty(&o.LoggerKey, c.LoggerKey)
+		setIfNotEmpty(&o.MessageKey, c.MessageKey)
+		setIfNotEmpty(&o.CallerKey, c.CallerKey)
+		setIfNotEmpty(&o.FunctionKey, c.FunctionKey)
+		setIfNotEmpty(&o.StackKey, c.StackKey)
+		setIfNotEmpty(&o.TimeFormat, c.TimeFormat)
+		o.LowercaseLevel = c.LowercaseLevel
+		o.FieldsKey = c.FieldsKey
+		o.Fields = c.Fields
+		o.OmitEmptyMessage = c.OmitEmptyMessage
+		o.OmitEmptyLogger = c.OmitEmptyLogger
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fields: %w", err)
+	}
+
+	return je, nil
 }
 
 func (je *jsonEncoder) Encode(e *LogEvent) ([]byte, error) {
 	je.locker.Lock()
 	defer je.locker.Unlock()
 
-	var err error
-	bufData := je.tsBuf.Bytes()
-	bufData, err = appendFormatUnix(bufData, e.Timestamp(), TimeFormatRFC3339)
+	timestamp, isString, err := je.formatTimestamp(e.Timestamp())
 	if err != nil {
 		return nil, err
 	}
-	je.tsBuf.Reset()
-	je.tsBuf.Write(bufData)
-	timestamp := je.tsBuf.Bytes()
-	je.tsBuf.Reset()
 
 	// write key and value as json string
 	je.buf.WriteString("{")
-	je.writeKeyAndValue(TimestampFieldKey, timestamp, true)
-	je.writeKeyAndValue(LevelFieldKey, e.Level(), true)
-	je.writeKeyAndValue(LoggerNameFieldKey, e.LoggerName(), true)
-	je.writeKeyAndValue(MessageFieldKey, e.Message(), true)
+	je.writeKeyAndValue(je.opts.TimeKey, timestamp, isString)
+	je.writeLevel(e.Level())
+	if logger := e.LoggerName(); len(logger) != 0 || !je.opts.OmitEmptyLogger {
+		je.writeKeyAndValue(je.opts.LoggerKey, logger, true)
+	}
+	if caller := e.Caller(); len(caller) != 0 {
+		je.writeKeyAndValue(je.opts.CallerKey, caller, true)
+		if fn := e.CallerFunc(); len(fn) != 0 {
+			je.writeKeyAndValue(je.opts.FunctionKey, fn, true)
+		}
+	}
+	if message := e.Message(); len(message) != 0 || !je.opts.OmitEmptyMessage {
+		je.writeKeyAndValue(je.opts.MessageKey, message, true)
+	}
+	je.buf.Write(je.fields)
 
-	_ = e.Fields(func(k, v []byte, isString bool) error {
-		je.writeKeyAndValue(string(k), v, isString)
-		return nil
-	})
+	je.writeFields(e)
+
+	if stack := e.Stack(); len(stack) != 0 {
+		je.writeKeyAndValue(je.opts.StackKey, stack, false)
+	}
 
 	je.buf.Truncate(je.buf.Len() - 1)
 	je.buf.WriteString("}\n")
@@ -70,6 +200,77 @@ func (je *jsonEncoder) Encode(e *LogEvent) ([]byte, error) {
 	return p, err
 }
 
+// formatTimestamp formats timestamp with time format, it returns false if the
+// timestamp is epoch number.
+func (je *jsonEncoder) formatTimestamp(unixNano int64) ([]byte, bool, error) {
+	var err error
+	bufData := je.tsBuf.Bytes()
+	isString := false
+	switch je.opts.TimeFormat {
+	case TimeFormatUnix:
+		bufData = strconv.AppendInt(bufData, unixNano/1e9, 10)
+	case TimeFormatUnixMilli:
+		bufData = strconv.AppendInt(bufData, unixNano/1e6, 10)
+	case TimeFormatUnixNano:
+		bufData = strconv.AppendInt(bufData, unixNano, 10)
+	default:
+		isString = true
+		bufData, err = appendFormatUnix(bufData, unixNano, je.opts.TimeFormat)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	je.tsBuf.Reset()
+	je.tsBuf.Write(bufData)
+	timestamp := je.tsBuf.Bytes()
+	je.tsBuf.Reset()
+
+	return timestamp, isString, nil
+}
+
+func (je *jsonEncoder) writeLevel(level []byte) {
+	je.writeKeyAndValue(je.opts.LevelKey, level, true)
+	if !je.opts.LowercaseLevel {
+		return
+	}
+
+	// level is written as "LEVEL",
+	p := je.buf.Bytes()
+	for i := len(p) - len(level) - 2; i < len(p)-2; i++ {
+		if p[i] >= 'A' && p[i] <= 'Z' {
+			p[i] += 'a' - 'A'
+		}
+	}
+}
+
+// writeFields writes the fields of event, they will be nested if FieldsKey is set.
+func (je *jsonEncoder) writeFields(e *LogEvent) {
+	nested := len(je.opts.FieldsKey) != 0
+	start := je.buf.Len()
+	if nested {
+		je.buf.WriteByte('"')
+		je.buf.WriteString(je.opts.FieldsKey)
+		je.buf.WriteString(`":{`)
+	}
+	mark := je.buf.Len()
+
+	_ = e.Fields(func(k, v []byte, isString bool) error {
+		je.writeKeyAndValue(string(k), v, isString)
+		return nil
+	})
+
+	if !nested {
+		return
+	}
+	if je.buf.Len() == mark {
+		// no fields, remove the nested key
+		je.buf.Truncate(start)
+		return
+	}
+	je.buf.Truncate(je.buf.Len() - 1)
+	je.buf.WriteString("},")
+}
+
 func (je *jsonEncoder) writeKeyAndValue(key string, value []byte, isString bool) {
 	je.buf.WriteByte('"')
 	je.buf.WriteString(key)
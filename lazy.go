@@ -0,0 +1,80 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// maxLogValuerDepth is the max depth to resolve LogValuer which returns LogValuer.
+const maxLogValuerDepth = 16
+
+// LogValuer is implemented by any value whose log value will be resolved lazily. Add
+// it with Record.Any, the LogValue will be called only once before the event is written
+// into writers, so it will never be called if the level is disabled.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// Lazy is a function implements LogValuer.
+type Lazy func() interface{}
+
+func (f Lazy) LogValue() interface{} {
+	return f()
+}
+
+// ResolveLogValue resolves the value of LogValuer. If the value is LogValuer again,
+// it will be resolved until it's not LogValuer.
+func ResolveLogValue(v LogValuer) (val interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			val = fmt.Sprintf("LogValue panicked: %v", r)
+		}
+	}()
+
+	val = v.LogValue()
+	for i := 0; i < maxLogValuerDepth; i++ {
+		valuer, ok := val.(LogValuer)
+		if !ok {
+			return val
+		}
+		val = valuer.LogValue()
+	}
+
+	return fmt.Sprintf("LogValue exceeds max depth %d", maxLogValuerDepth)
+}
+
+// NewLazyMarshaler creates a json.Marshaler which resolves the LogValuer when it's
+// marshaled. This is useful for providers which encode fields with json.
+func NewLazyMarshaler(v LogValuer) json.Marshaler {
+	return &lazyMarshaler{
+		valuer: v,
+	}
+}
+
+type lazyMarshaler struct {
+	valuer LogValuer
+}
+
+func (m *lazyMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ResolveLogValue(m.valuer))
+}
+
+// lazyField is a field whose value will be resolved lazily.
+type lazyField struct {
+	key    string
+	valuer LogValuer
+}
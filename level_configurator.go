@@ -0,0 +1,129 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// LevelEnvKey is the environment variable of level spec, which will be applied when
+// provider prepares.
+const LevelEnvKey = "LORK_LEVEL"
+
+// LevelConfigurator configures levels of loggers with level spec such as
+// "info,github.com/acme/db=debug,github.com/acme/http=warn". The level without
+// logger name is for root logger.
+type LevelConfigurator struct {
+	locker  sync.Mutex
+	spec    string
+	context *LoggerContext
+}
+
+type loggerLevel struct {
+	name  string
+	level Level
+}
+
+var levels = &LevelConfigurator{}
+
+// Levels gets LevelConfigurator to use. It runs before ManualConfigurator, and the
+// spec in environment variable LORK_LEVEL overrides the spec set programmatically and
+// the levels in config file, even if the config file is reloaded.
+func Levels() *LevelConfigurator {
+	return levels
+}
+
+// SetSpec sets the level spec, it will be applied immediately if configured.
+func (c *LevelConfigurator) SetSpec(spec string) error {
+	parsed, err := parseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	c.spec = spec
+	if c.context != nil {
+		applyLevels(c.context, parsed)
+		applyEnvLevels(c.context)
+	}
+
+	return nil
+}
+
+func (c *LevelConfigurator) Configure(ctx *LoggerContext) ExecutionStatus {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	c.context = ctx
+	if parsed, err := parseLevelSpec(c.spec); err != nil {
+		Reportf("invalid level spec [%s]: %v", c.spec, err)
+	} else {
+		applyLevels(ctx, parsed)
+	}
+	applyEnvLevels(ctx)
+
+	return StatusNext
+}
+
+// applyEnvLevels applies the level spec in environment variable, it should be called
+// after levels are changed by any other configuration.
+func applyEnvLevels(ctx *LoggerContext) {
+	spec := os.Getenv(LevelEnvKey)
+	parsed, err := parseLevelSpec(spec)
+	if err != nil {
+		Reportf("invalid level spec [%s]: %v", spec, err)
+		return
+	}
+	applyLevels(ctx, parsed)
+}
+
+// parseLevelSpec parses level spec into levels of loggers.
+func parseLevelSpec(spec string) ([]loggerLevel, error) {
+	var parsed []loggerLevel
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		name, lvl := RootLoggerName, entry
+		if index := strings.LastIndexByte(entry, '='); index >= 0 {
+			name = strings.TrimSpace(entry[:index])
+			lvl = entry[index+1:]
+		}
+		if len(name) == 0 {
+			return nil, fmt.Errorf("missing logger name in [%s]", entry)
+		}
+
+		level, ok := LookupLevel(lvl)
+		if !ok {
+			return nil, fmt.Errorf("invalid level in [%s]", entry)
+		}
+		parsed = append(parsed, loggerLevel{name: name, level: level})
+	}
+
+	return parsed, nil
+}
+
+func applyLevels(ctx *LoggerContext, parsed []loggerLevel) {
+	for _, ll := range parsed {
+		ctx.RealLogger(ll.name).SetLevel(ll.level)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"os"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("level configurator", func() {
+	ginkgo.It("parse level spec", func() {
+		parsed, err := parseLevelSpec(" info, github.com/acme/db=debug ,,github.com/acme/http=WARN")
+		Expect(err).To(BeNil())
+		Expect(parsed).To(Equal([]loggerLevel{
+			{name: RootLoggerName, level: InfoLevel},
+			{name: "github.com/acme/db", level: DebugLevel},
+			{name: "github.com/acme/http", level: WarnLevel},
+		}))
+
+		_, err = parseLevelSpec("github.com/acme/db=verbose")
+		Expect(err).NotTo(BeNil())
+		_, err = parseLevelSpec("=debug")
+		Expect(err).NotTo(BeNil())
+	})
+	ginkgo.It("configure with spec and environment", func() {
+		Expect(os.Setenv(LevelEnvKey, "github.com/acme/http=error")).To(BeNil())
+		defer os.Unsetenv(LevelEnvKey)
+
+		c := &LevelConfigurator{}
+		Expect(c.SetSpec("warn,github.com/acme/db=debug,github.com/acme/http=info")).To(BeNil())
+		ctx := NewLoggerContext(NewClassicLogger)
+		Expect(c.Configure(ctx)).To(Equal(StatusNext))
+
+		Expect(ctx.RealLogger(RootLoggerName).EffectiveLevel()).To(Equal(WarnLevel))
+		Expect(ctx.RealLogger("github.com/acme/db/sql").EffectiveLevel()).To(Equal(DebugLevel))
+		Expect(ctx.RealLogger("github.com/acme/http").EffectiveLevel()).To(Equal(ErrorLevel))
+
+		Expect(c.SetSpec("github.com/acme/db=trace")).To(BeNil())
+		Expect(ctx.RealLogger("github.com/acme/db/sql").EffectiveLevel()).To(Equal(TraceLevel))
+		Expect(c.SetSpec("github.com/acme/db=verbose")).NotTo(BeNil())
+	})
+})
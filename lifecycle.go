@@ -14,6 +14,16 @@
 
 package lork
 
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const flushPollInterval = time.Millisecond
+
 // Lifecycle represents the lifecycle of component.
 type Lifecycle interface {
 	// Start the component.
@@ -21,3 +31,89 @@ type Lifecycle interface {
 	// Stop the component.
 	Stop()
 }
+
+// CheckedStarter represents a component which may fail to start, the error will be
+// returned instead of exiting the process, so a failed reload can be rolled back.
+type CheckedStarter interface {
+	// StartChecked starts the component, and returns the error if it fails.
+	StartChecked() error
+}
+
+// startChecked starts the given component with StartChecked if it implements
+// CheckedStarter, or starts it if it implements Lifecycle.
+func startChecked(v interface{}) error {
+	if cs, ok := v.(CheckedStarter); ok {
+		return cs.StartChecked()
+	} else if lc, ok := v.(Lifecycle); ok {
+		lc.Start()
+	}
+
+	return nil
+}
+
+// ContextStopper represents a component which stops gracefully until the context
+// is done, the buffered data not written before that will be dropped.
+type ContextStopper interface {
+	// StopContext stops the component, and gives up waiting when ctx is done.
+	StopContext(ctx context.Context)
+}
+
+// stopContext stops the given component with ctx if it implements ContextStopper,
+// or stops it if it implements Lifecycle.
+func stopContext(ctx context.Context, v interface{}) {
+	if cs, ok := v.(ContextStopper); ok {
+		cs.StopContext(ctx)
+	} else if lc, ok := v.(Lifecycle); ok {
+		lc.Stop()
+	}
+}
+
+// Flushable represents a component which buffers data and can flush it.
+type Flushable interface {
+	// Flush blocks until all the buffered data is written or the context is done.
+	Flush(ctx context.Context) error
+}
+
+// flush flushes the given component if it implements Flushable.
+func flush(ctx context.Context, v interface{}) error {
+	if f, ok := v.(Flushable); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
+
+// waitGroupDone waits until the wait group is done or the context is done.
+func waitGroupDone(ctx context.Context, wg *sync.WaitGroup, what string) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", what, ctx.Err())
+	}
+}
+
+// waitPending waits until the pending count drops to zero or the context is done.
+func waitPending(ctx context.Context, pending *int64) error {
+	ticker := time.NewTicker(flushPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n := atomic.LoadInt64(pending)
+		if n <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%d events pending: %w", n, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/buger/jsonparser"
+)
+
+var (
+	emptyJsonArray  = []byte("[]")
+	emptyJsonObject = []byte("{}")
+)
+
+// LogfmtEncoderOption represents available options for logfmt encoder.
+type LogfmtEncoderOption struct {
+	TimeKey     string
+	LevelKey    string
+	LoggerKey   string
+	MessageKey  string
+	CallerKey   string
+	FunctionKey string
+	StackKey    string
+	// TimeFormat is the layout to format timestamp, default is TimeFormatRFC3339.
+	TimeFormat string
+}
+
+// logfmtEncoder encodes logging event into logfmt format.
+type logfmtEncoder struct {
+	opts   *LogfmtEncoderOption
+	locker sync.Mutex
+	buf    []byte
+	tmp    []byte
+	path   []byte
+}
+
+// NewLogfmtEncoder creates a new instance of encoder to encode data to logfmt, such as
+// time=... level=INFO logger=main msg="hello world" key=value. Nested objects and
+// arrays are flattened with dotted keys, e.g. a.b=1 tags.0=x.
+func NewLogfmtEncoder(options ...func(*LogfmtEncoderOption)) Encoder {
+	opts := &LogfmtEncoderOption{
+		TimeKey:     "time",
+		LevelKey:    "level",
+		LoggerKey:   "logger",
+		MessageKey:  "msg",
+		CallerKey:   "caller",
+		FunctionKey: "func",
+		StackKey:    "stack",
+		TimeFormat:  TimeFormatRFC3339,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	return &logfmtEncoder{
+		opts: opts,
+	}
+}
+
+func init() {
+	RegisterEncoder("logfmt", newLogfmtEncoderFromConfig)
+}
+
+type logfmtEncoderConfig struct {
+	TimeKey     string `json:"time_key"`
+	LevelKey    string `json:"level_key"`
+	LoggerKey   string `json:"logger_key"`
+	MessageKey  string `json:"message_key"`
+	CallerKey   string `json:"caller_key"`
+	FunctionKey string `json:"function_key"`
+	StackKey    string `json:"stack_key"`
+	TimeFormat  string `json:"time_format"`
+}
+
+func newLogfmtEncoderFromConfig(d OptionDecoder) (Encoder, error) {
+	var c logfmtEncoderConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	return NewLogfmtEncoder(func(o *LogfmtEncoderOption) {
+		setIfNotEmpty(&o.TimeKey, c.TimeKey)
+		setIfNotEmpty(&o.LevelKey, c.LevelKey)
+		setIfNotEmpty(&o.LoggerKey, c.LoggerKey)
+		setIfNotEmpty(&o.MessageKey, c.MessageKey)
+		setIfNotEmpty(&o.CallerKey, c.CallerKey)
+		setIfNotEmpty(&o.FunctionKey, c.FunctionKey)
+		setIfNotEmpty(&o.StackKey, c.StackKey)
+		setIfNotEmpty(&o.TimeFormat, c.TimeFormat)
+	}), nil
+}
+
+func (le *logfmtEncoder) Encode(e *LogEvent) ([]byte, error) {
+	le.locker.Lock()
+	defer le.locker.Unlock()
+
+	var err error
+	le.buf = le.buf[:0]
+	le.buf = append(le.buf, le.opts.TimeKey...)
+	le.buf = append(le.buf, '=')
+	if le.buf, err = appendFormatUnix(le.buf, e.Timestamp(), le.opts.TimeFormat); err != nil {
+		return nil, err
+	}
+	le.writeKeyAndValue(le.opts.LevelKey, e.Level())
+	le.writeKeyAndValue(le.opts.LoggerKey, e.LoggerName())
+	if caller := e.Caller(); len(caller) != 0 {
+		le.writeKeyAndValue(le.opts.CallerKey, caller)
+		if fn := e.CallerFunc(); len(fn) != 0 {
+			le.writeKeyAndValue(le.opts.FunctionKey, fn)
+		}
+	}
+	le.writeKeyAndValue(le.opts.MessageKey, e.Message())
+
+	_ = e.Fields(func(k, v []byte, isString bool) error {
+		le.path = append(le.path[:0], k...)
+		le.writeField(v, isString)
+		return nil
+	})
+
+	if stack := e.Stack(); len(stack) != 0 {
+		le.path = append(le.path[:0], le.opts.StackKey...)
+		le.writeField(stack, false)
+	}
+	le.buf = append(le.buf, '\n')
+
+	return le.buf, nil
+}
+
+// writeField writes the field with current path as key, the members of object and
+// the elements of array are flattened with dotted path. Empty object and array are
+// written as {} and [].
+func (le *logfmtEncoder) writeField(v []byte, isString bool) {
+	if isString {
+		if cap(le.tmp) < len(v) {
+			le.tmp = make([]byte, len(v))
+		}
+		unescaped, err := jsonparser.Unescape(v, le.tmp[:cap(le.tmp)])
+		if err != nil {
+			unescaped = v
+		}
+		le.writePathAndValue(unescaped)
+		return
+	}
+
+	n := len(le.path)
+	defer func() {
+		le.path = le.path[:n]
+	}()
+
+	switch {
+	case isJsonObject(v):
+		_ = jsonparser.ObjectEach(v, func(k []byte, mv []byte,
+			dataType jsonparser.ValueType, _ int) error {
+			le.path = append(append(le.path[:n], '.'), k...)
+			le.writeField(mv, dataType == jsonparser.String)
+			return nil
+		})
+	case isJsonArray(v):
+		var index int64
+		_, _ = jsonparser.ArrayEach(v, func(av []byte, dataType jsonparser.ValueType,
+			_ int, _ error) {
+			le.path = strconv.AppendInt(append(le.path[:n], '.'), index, 10)
+			index++
+			le.writeField(av, dataType == jsonparser.String)
+		})
+		if index == 0 {
+			le.writePathAndValue(emptyJsonArray)
+		}
+	case len(v) != 0 && v[0] == '{':
+		le.writePathAndValue(emptyJsonObject)
+	default:
+		le.writePathAndValue(v)
+	}
+}
+
+func (le *logfmtEncoder) writePathAndValue(value []byte) {
+	le.buf = append(le.buf, ' ')
+	le.buf = appendLogfmtKey(le.buf, le.path)
+	le.buf = append(le.buf, '=')
+	le.buf = appendLogfmtValue(le.buf, value)
+}
+
+func (le *logfmtEncoder) writeKeyAndValue(key string, value []byte) {
+	le.buf = append(le.buf, ' ')
+	le.buf = append(le.buf, key...)
+	le.buf = append(le.buf, '=')
+	le.buf = appendLogfmtValue(le.buf, value)
+}
+
+// appendLogfmtKey appends key to dst, the characters not allowed in key are
+// replaced with underscore.
+func appendLogfmtKey(dst, key []byte) []byte {
+	if len(key) == 0 {
+		return append(dst, '_')
+	}
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+
+	return dst
+}
+
+// appendLogfmtValue appends value to dst, it will be quoted and escaped if it's empty
+// or contains space, equal sign, quote or control characters.
+func appendLogfmtValue(dst, value []byte) []byte {
+	if !needsLogfmtQuote(value) {
+		return append(dst, value...)
+	}
+
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(value); {
+		c := value[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(value[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, value[start:i]...)
+				dst = append(dst, "�"...)
+				i += size
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+		if c >= 0x20 && c != '"' && c != '\\' && c != 0x7f {
+			i++
+			continue
+		}
+
+		dst = append(dst, value[start:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+		}
+		i++
+		start = i
+	}
+	dst = append(dst, value[start:]...)
+
+	return append(dst, '"')
+}
+
+func needsLogfmtQuote(value []byte) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for _, c := range value {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+	}
+
+	return !utf8.Valid(value)
+}
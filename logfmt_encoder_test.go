@@ -0,0 +1,61 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("logfmt encoder", func() {
+	event := MakeEvent([]byte(`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00",` +
+		`"logger_name":"github.com/lork","message":"hello \"lork\"\n","str":"a b",` +
+		`"empty":"","eq":"a=b","num":1.5,"ok":true,"nil":null,"uni":"中文","esc":"tab\there",` +
+		`"user":{"name":"lork","tags":["a","b c"],"point":{"x":1}},"list":[{"k":"v"},2],` +
+		`"bad key":1,"arr":[],"obj":{}}`))
+	var data []byte
+	rt, _ := appendFormatUnix(data, event.Timestamp(), TimeFormatRFC3339)
+
+	ginkgo.It("encode", func() {
+		out, err := NewLogfmtEncoder().Encode(event)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal("time=" + string(rt) + ` level=INFO logger=github.com/lork ` +
+			`msg="hello \"lork\"" str="a b" empty="" eq="a=b" num=1.5 ok=true nil=null ` +
+			`uni=中文 esc="tab\there" user.name=lork user.tags.0=a user.tags.1="b c" ` +
+			`user.point.x=1 list.0.k=v list.1=2 bad_key=1 arr=[] obj={}` + "\n"))
+	})
+	ginkgo.It("flatten stack", func() {
+		stackEvent := MakeEvent([]byte(`{"level":"ERROR","time":"2019-12-27T10:40:14+08:00",` +
+			`"logger_name":"main","message":"failed",` +
+			`"stack":[{"func":"main.work","file":"/go/src/app/main.go","line":12}]}`))
+		out, err := NewLogfmtEncoder().Encode(stackEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(` msg=failed stack.0.func=main.work ` +
+			`stack.0.file=/go/src/app/main.go stack.0.line=12` + "\n"))
+	})
+	ginkgo.It("custom keys", func() {
+		out, err := NewLogfmtEncoder(func(o *LogfmtEncoderOption) {
+			o.TimeKey = "ts"
+			o.LevelKey = "lvl"
+			o.LoggerKey = "name"
+			o.MessageKey = "message"
+			o.TimeFormat = "2006"
+		}).Encode(callerEvent)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`ts=2019 lvl=INFO name="" ` +
+			`caller=/go/src/github.com/coolerfall/lork/event.go:42 ` +
+			`func=github.com/coolerfall/lork.MakeEvent message=hello` + "\n"))
+	})
+})
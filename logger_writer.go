@@ -0,0 +1,183 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"context"
+)
+
+// Additive represents a logger whose events can propagate to the writers of ancestors.
+type Additive interface {
+	// SetAdditive sets if the events of this logger will also be written into
+	// the writers of ancestors, it's true by default.
+	SetAdditive(additive bool)
+}
+
+// loggerWriters holds the writers attached to a named logger, it's immutable once
+// stored into writerRoutes.
+type loggerWriters struct {
+	writers  []Writer
+	additive bool
+}
+
+// writerRoutes routes events to the writers attached to named loggers by logger name.
+// The writers are stored in the writer set of multi writer with copy-on-write to keep
+// writing lock free.
+type writerRoutes struct {
+	mw *MultiWriter
+}
+
+func newWriterRoutes(mw *MultiWriter) *writerRoutes {
+	return &writerRoutes{
+		mw: mw,
+	}
+}
+
+// add starts and adds writers for the logger with given name.
+func (wr *writerRoutes) add(name string, writers ...Writer) {
+	startWriters(writers)
+
+	wr.update(name, func(lw *loggerWriters) {
+		lw.writers = append(append([]Writer(nil), lw.writers...), writers...)
+	})
+}
+
+// get gets the writer with given writer name of the logger with given name.
+func (wr *writerRoutes) get(name, writerName string) Writer {
+	lw, ok := wr.load()[name]
+	if !ok {
+		return nil
+	}
+
+	for _, w := range lw.writers {
+		if w.Name() == writerName {
+			return w
+		}
+	}
+
+	return nil
+}
+
+// attached checks if the writer is attached to the logger with given name.
+func (wr *writerRoutes) attached(name string, writer Writer) bool {
+	lw, ok := wr.load()[name]
+	if !ok {
+		return false
+	}
+
+	for _, w := range lw.writers {
+		if w == writer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setAdditive sets the additivity of the logger with given name.
+func (wr *writerRoutes) setAdditive(name string, additive bool) {
+	wr.update(name, func(lw *loggerWriters) {
+		lw.additive = additive
+	})
+}
+
+// reset removes and stops the writers of the logger with given name.
+func (wr *writerRoutes) reset(name string) {
+	var writers []Writer
+	wr.update(name, func(lw *loggerWriters) {
+		writers = lw.writers
+		lw.writers = nil
+	})
+	wr.mw.grace(context.Background())
+	stopWriters(context.Background(), writers)
+}
+
+// resetAll removes and stops the writers of all named loggers, until ctx is done.
+func (wr *writerRoutes) resetAll(ctx context.Context) {
+	wr.mw.locker.Lock()
+	old := wr.mw.update(func(set *writerSet) {
+		set.named = map[string]*loggerWriters{}
+	})
+	wr.mw.locker.Unlock()
+
+	wr.mw.grace(ctx)
+	for _, lw := range old.named {
+		stopWriters(ctx, lw.writers)
+	}
+}
+
+// lookup returns the writers and additivity of the logger with given name.
+func (wr *writerRoutes) lookup(name string) ([]Writer, bool) {
+	lw, ok := wr.load()[name]
+	if !ok {
+		return nil, true
+	}
+
+	return lw.writers, lw.additive
+}
+
+// empty checks if there's no writer attached to named loggers.
+func (wr *writerRoutes) empty() bool {
+	for _, lw := range wr.load() {
+		if len(lw.writers) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (wr *writerRoutes) load() map[string]*loggerWriters {
+	return wr.mw.load().named
+}
+
+// update copies the writers of the logger with given name and stores it after
+// updated, the old writer set will be returned.
+func (wr *writerRoutes) update(name string, f func(lw *loggerWriters)) *writerSet {
+	wr.mw.locker.Lock()
+	defer wr.mw.locker.Unlock()
+
+	return wr.mw.update(func(set *writerSet) {
+		lw := &loggerWriters{additive: true}
+		if v, ok := set.named[name]; ok {
+			*lw = *v
+		}
+		f(lw)
+		set.named[name] = lw
+	})
+}
+
+// writeNamed writes event into the writers of the logger and its ancestors, from the
+// logger itself to the top ancestor. It returns false if any of them is not additive,
+// which means the event should not be written into the writers of root logger.
+func writeNamed(named map[string]*loggerWriters, e *LogEvent) bool {
+	if len(named) == 0 {
+		return true
+	}
+
+	name := e.LoggerName()
+	for end := len(name); end > 0; {
+		if lw, ok := named[string(name[:end])]; ok {
+			writeTo(lw.writers, e)
+			if !lw.additive {
+				return false
+			}
+		}
+		end = bytes.LastIndexByte(name[:end], '/')
+	}
+
+	return true
+}
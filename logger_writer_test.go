@@ -0,0 +1,138 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("logger writer", func() {
+	var ctx *LoggerContext
+	var root *eventCollector
+	var db *eventCollector
+
+	ginkgo.BeforeEach(func() {
+		ctx, root = newCollectorContext()
+		db = &eventCollector{}
+		ctx.RealLogger("github.com/acme/db").AddWriter(db)
+	})
+
+	ginkgo.It("additivity", func() {
+		ctx.Logger("github.com/acme/db/sql").Info().Msg("query")
+		Expect(db.events).To(HaveLen(1))
+		Expect(root.events).To(HaveLen(1))
+
+		ctx.Logger("github.com/acme").Info().Msg("acme")
+		Expect(db.events).To(HaveLen(1))
+		Expect(root.events).To(HaveLen(2))
+
+		ctx.RealLogger("github.com/acme/db").SetAdditive(false)
+		ctx.Logger("github.com/acme/db").Info().Msg("not additive")
+		Expect(string(db.last().Message())).To(Equal("not additive"))
+		Expect(root.events).To(HaveLen(2))
+	})
+	ginkgo.It("attach and reset", func() {
+		logger := ctx.RealLogger("github.com/acme/db")
+		Expect(logger.GetWriter("COLLECTOR")).To(Equal(db))
+		Expect(logger.Attached(db)).To(BeTrue())
+		Expect(ctx.RealLogger("github.com/acme").Attached(db)).To(BeFalse())
+
+		logger.ResetWriter()
+		Expect(logger.Attached(db)).To(BeFalse())
+		ctx.Logger("github.com/acme/db").Info().Msg("reset")
+		Expect(db.events).To(BeEmpty())
+		Expect(root.events).To(HaveLen(1))
+	})
+	ginkgo.It("manual configurator", func() {
+		access := &eventCollector{}
+		c := &ManualConfigurator{}
+		c.ConfigLogger("github.com/acme/http", func(o *LoggerOption) {
+			o.Writers = []Writer{access}
+			o.Additive = false
+		})
+		c.attach(ctx)
+		Expect(c.Configure(ctx)).To(Equal(StatusNext))
+
+		ctx.Logger("github.com/acme/http/access").Info().Msg("access")
+		Expect(access.events).To(HaveLen(1))
+		Expect(root.events).To(BeEmpty())
+
+		c.ResetWriter()
+		ctx.Logger("github.com/acme/http").Info().Msg("after reset")
+		Expect(access.events).To(HaveLen(1))
+	})
+	ginkgo.It("install manual loggers when chain stops early", func() {
+		saved := manual
+		manual = &ManualConfigurator{}
+		defer func() { manual = saved }()
+
+		access := &eventCollector{}
+		manual.ConfigLogger("github.com/acme/http", func(o *LoggerOption) {
+			o.Writers = []Writer{access}
+			o.Additive = false
+		})
+		p := NewBaseProvider(ctx)
+		p.configurators = []Configurator{stopConfigurator{}}
+		p.Prepare()
+
+		ctx.Logger("github.com/acme/http").Info().Msg("access")
+		Expect(access.events).To(HaveLen(1))
+		Expect(root.events).To(BeEmpty())
+	})
+	ginkgo.It("write without blocking by slow writer", func() {
+		gated := &gatedWriter{gate: make(chan struct{})}
+		slow := ctx.RealLogger("github.com/acme/slow")
+		slow.AddWriter(gated)
+		slow.SetAdditive(false)
+
+		written := make(chan struct{})
+		go func() {
+			ctx.Logger("github.com/acme/slow").Info().Msg("slow")
+			close(written)
+		}()
+		mw := ctx.rootLogger.multiWriter
+		Eventually(func() int64 {
+			return atomic.LoadInt64(&mw.inflight[0]) + atomic.LoadInt64(&mw.inflight[1])
+		}).Should(Equal(int64(1)))
+
+		// changing and using other writers is not blocked by the slow one
+		ctx.RealLogger(RootLoggerName).AddWriter(&eventCollector{})
+		ctx.Logger("github.com/acme/db").Info().Msg("db")
+		Expect(db.events).To(HaveLen(1))
+
+		// the slow writer is stopped after the event being written is finished
+		reset := make(chan struct{})
+		go func() {
+			slow.ResetWriter()
+			close(reset)
+		}()
+		Consistently(reset, 20*time.Millisecond).ShouldNot(BeClosed())
+		close(gated.gate)
+		Eventually(written).Should(BeClosed())
+		Eventually(reset).Should(BeClosed())
+		Expect(gated.written).To(Equal(1))
+	})
+})
+
+type stopConfigurator struct {
+}
+
+func (c stopConfigurator) Configure(*LoggerContext) ExecutionStatus {
+	return StatusNoNext
+}
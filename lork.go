@@ -27,14 +27,23 @@ const (
 	ErrorLevel
 	FatalLevel
 	PanicLevel
+	OffLevel
 )
 
 const (
-	LevelFieldKey      = "level"
-	TimestampFieldKey  = "time"
-	MessageFieldKey    = "message"
-	LoggerNameFieldKey = "logger_name"
-	ErrorFieldKey      = "error"
+	LevelFieldKey        = "level"
+	TimestampFieldKey    = "time"
+	MessageFieldKey      = "message"
+	LoggerNameFieldKey   = "logger_name"
+	ErrorFieldKey        = "error"
+	ErrorTypeFieldKey    = "error_type"
+	ErrorDetailsFieldKey = "error_details"
+	ErrorCausesFieldKey  = "error_causes"
+	CallerFieldKey       = "caller"
+	FunctionFieldKey     = "func"
+	StackFieldKey        = "stack"
+	TraceIdFieldKey      = "trace_id"
+	SpanIdFieldKey       = "span_id"
 
 	TimestampFormat   = time.RFC3339Nano
 	TimeFormatRFC3339 = "2006-01-02T15:04:05.000Z07:00"
@@ -51,6 +60,7 @@ var (
 		"ERROR": ErrorLevel,
 		"FATAL": FatalLevel,
 		"PANIC": PanicLevel,
+		"OFF":   OffLevel,
 	}
 )
 
@@ -70,6 +80,19 @@ type ILogger interface {
 	// SetLevel sets global level for logger.
 	SetLevel(lvl Level)
 
+	// SetCaller enables or disables caller capturing for logger. The argument skip is
+	// the number of extra stack frames to ascend, which is useful for logging wrappers.
+	SetCaller(enabled bool, skip int)
+
+	// SetStackLevel sets the level at or above which stack trace will be captured
+	// automatically. Use OffLevel to disable it.
+	SetStackLevel(lvl Level)
+
+	// With creates a child logger with the fields added in the given function. The
+	// fields will be encoded only once and added to every record of the child logger.
+	// The child logger shares level with this logger. Note: do not call Msg in fields.
+	With(fields func(r Record)) ILogger
+
 	// Trace logs with trace level.
 	Trace() Record
 
@@ -85,10 +108,12 @@ type ILogger interface {
 	// Error logs with error level.
 	Error() Record
 
-	// Fatal logs with fatal level.
+	// Fatal logs with fatal level, all writers will be flushed and then the exit
+	// function set by SetExitFunc will be called after the record is written.
 	Fatal() Record
 
-	// Panic logs with panic level.
+	// Panic logs with panic level, all writers will be flushed and then it panics
+	// with the message after the record is written.
 	Panic() Record
 
 	// Level logs with specified level.
@@ -98,6 +123,19 @@ type ILogger interface {
 	Event(e *LogEvent)
 }
 
+// LevelInheritable represents a logger in hierarchy which inherits the level from
+// the nearest ancestor with explicit level if no explicit level set.
+type LevelInheritable interface {
+	// EffectiveLevel returns the explicit level, or the level inherited from ancestors.
+	EffectiveLevel() Level
+
+	// ExplicitLevel returns the explicit level and true if it's set.
+	ExplicitLevel() (Level, bool)
+
+	// ResetLevel removes the explicit level, and the level will be inherited.
+	ResetLevel()
+}
+
 // ILoggerFactory represents a factory to get loggers.
 type ILoggerFactory interface {
 	// Logger gets a ILogger with given name
@@ -124,6 +162,16 @@ type Bridge interface {
 	ParseLevel(lvl string) Level
 }
 
+// RoutingBridge is an optional interface of Bridge. The logs of bridge which routes
+// by logger name will be written to the logger named by the logger name field in logs,
+// otherwise they will be written to the logger named by bridge.
+type RoutingBridge interface {
+	Bridge
+
+	// RouteByLoggerName returns true if logs should be routed by logger name.
+	RouteByLoggerName() bool
+}
+
 // Logger gets a global lork logger to use. The name will only get the first one.
 func Logger(name ...string) ILogger {
 	realName := ""
@@ -154,6 +202,8 @@ func (l Level) String() string {
 		return "FATAL"
 	case PanicLevel:
 		return "PANIC"
+	case OffLevel:
+		return "OFF"
 	case TraceLevel:
 		fallthrough
 	default:
@@ -163,10 +213,17 @@ func (l Level) String() string {
 
 // ParseLevel converts a level string into lork level value.
 func ParseLevel(lvl string) Level {
-	level, ok := levelMap[strings.ToUpper(lvl)]
+	level, ok := LookupLevel(lvl)
 	if !ok {
 		return TraceLevel
 	}
 
 	return level
 }
+
+// LookupLevel converts a level string into lork level value, and reports whether
+// the level string is valid.
+func LookupLevel(lvl string) (Level, bool) {
+	level, ok := levelMap[strings.ToUpper(strings.TrimSpace(lvl))]
+	return level, ok
+}
@@ -7,6 +7,8 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+var raceEnabled = false
+
 func TestLork(t *testing.T) {
 	RegisterFailHandler(ginkgo.Fail)
 	ginkgo.RunSpecs(t, "Lork suite tests")
@@ -16,12 +16,22 @@ package lork
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // namedLogger represents a logger with name which can be used as category.
 type namedLogger struct {
-	name  string
-	level Level
+	name string
+	// level is the effective level which is read atomically when making records,
+	// explicitLevel is valid only if hasLevel is true, or the level is inherited.
+	level         int32
+	explicitLevel Level
+	hasLevel      bool
+	// caller is read atomically when making records, the lowest bit is set if caller
+	// is enabled, and the other bits are the skip.
+	caller int32
+	// stackLevel is read atomically when making records.
+	stackLevel int32
 
 	realLogger ILogger
 	parent     ILogger
@@ -34,10 +44,13 @@ type namedLogger struct {
 // newNamedLogger creates a new instance of named logger.
 func newNamedLogger(name string, parent ILogger, writer *MultiWriter) *namedLogger {
 	nl := &namedLogger{
-		name:        name,
-		parent:      parent,
-		level:       TraceLevel,
-		multiWriter: writer,
+		name:          name,
+		parent:        parent,
+		level:         int32(TraceLevel),
+		explicitLevel: TraceLevel,
+		hasLevel:      name == RootLoggerName,
+		stackLevel:    int32(OffLevel),
+		multiWriter:   writer,
 	}
 	nl.realLogger = nl.findRealLogger()
 
@@ -48,22 +61,100 @@ func (nl *namedLogger) Name() string {
 	return nl.name
 }
 
+// SetLevel sets the explicit level of this logger, the children without explicit
+// level will inherit it.
 func (nl *namedLogger) SetLevel(lvl Level) {
 	nl.locker.Lock()
 	defer nl.locker.Unlock()
 
-	if nl.level == lvl {
-		// nothing to do
+	nl.explicitLevel = lvl
+	nl.hasLevel = true
+	nl.applyLevel(lvl)
+}
+
+// ResetLevel removes the explicit level of this logger, and the level will be
+// inherited from the nearest ancestor with explicit level. It does nothing for
+// root logger.
+func (nl *namedLogger) ResetLevel() {
+	parent, ok := nl.parent.(*namedLogger)
+	if !ok {
 		return
 	}
 
-	nl.level = lvl
+	// always lock parent before child to avoid dead lock
+	parent.locker.Lock()
+	defer parent.locker.Unlock()
+	nl.locker.Lock()
+	defer nl.locker.Unlock()
+
+	nl.hasLevel = false
+	nl.applyLevel(parent.EffectiveLevel())
+}
+
+// EffectiveLevel returns the explicit level, or the level inherited from ancestors.
+func (nl *namedLogger) EffectiveLevel() Level {
+	return Level(atomic.LoadInt32(&nl.level))
+}
+
+// ExplicitLevel returns the explicit level and true if it's set.
+func (nl *namedLogger) ExplicitLevel() (Level, bool) {
+	nl.locker.Lock()
+	defer nl.locker.Unlock()
+
+	return nl.explicitLevel, nl.hasLevel
+}
+
+// applyLevel sets the effective level and passes it to children without explicit
+// level, this must be called with locker held.
+func (nl *namedLogger) applyLevel(lvl Level) {
+	atomic.StoreInt32(&nl.level, int32(lvl))
 
 	for _, child := range nl.children {
-		child.SetLevel(lvl)
+		child.inheritLevel(lvl)
 	}
 }
 
+// inheritLevel applies level from parent if no explicit level set.
+func (nl *namedLogger) inheritLevel(lvl Level) {
+	nl.locker.Lock()
+	defer nl.locker.Unlock()
+
+	if nl.hasLevel {
+		return
+	}
+	nl.applyLevel(lvl)
+}
+
+func (nl *namedLogger) SetCaller(enabled bool, skip int) {
+	nl.locker.Lock()
+	defer nl.locker.Unlock()
+
+	caller := int32(skip) << 1
+	if enabled {
+		caller |= 1
+	}
+	atomic.StoreInt32(&nl.caller, caller)
+
+	for _, child := range nl.children {
+		child.SetCaller(enabled, skip)
+	}
+}
+
+func (nl *namedLogger) SetStackLevel(lvl Level) {
+	nl.locker.Lock()
+	defer nl.locker.Unlock()
+
+	atomic.StoreInt32(&nl.stackLevel, int32(lvl))
+
+	for _, child := range nl.children {
+		child.SetStackLevel(lvl)
+	}
+}
+
+func (nl *namedLogger) With(fields func(Record)) ILogger {
+	return newBoundLogger(nl, nl.realLogger.With(fields))
+}
+
 func (nl *namedLogger) Trace() Record {
 	return nl.makeRecord(TraceLevel, nl.realLogger.Trace)
 }
@@ -117,26 +208,92 @@ func (nl *namedLogger) Event(e *LogEvent) {
 	nl.parent.Event(e)
 }
 
+// AddWriter adds writers to this logger. The events of this logger and its children
+// will be written into these writers, and also the writers of ancestors if additive.
 func (nl *namedLogger) AddWriter(writers ...Writer) {
-	nl.multiWriter.AddWriter(writers...)
+	if nl.ownsWriters() {
+		nl.multiWriter.AddWriter(writers...)
+	} else {
+		nl.multiWriter.routes.add(nl.name, writers...)
+	}
 }
 
 func (nl *namedLogger) GetWriter(name string) Writer {
-	return nl.multiWriter.GetWriter(name)
+	if nl.ownsWriters() {
+		return nl.multiWriter.GetWriter(name)
+	}
+
+	return nl.multiWriter.routes.get(nl.name, name)
 }
 
 func (nl *namedLogger) Attached(writer Writer) bool {
-	return nl.multiWriter.Attached(writer)
+	if nl.ownsWriters() {
+		return nl.multiWriter.Attached(writer)
+	}
+
+	return nl.multiWriter.routes.attached(nl.name, writer)
 }
 
 func (nl *namedLogger) ResetWriter() {
-	nl.multiWriter.ResetWriter()
+	if nl.ownsWriters() {
+		nl.multiWriter.ResetWriter()
+	} else {
+		nl.multiWriter.routes.reset(nl.name)
+	}
+}
+
+// SetAdditive sets if the events of this logger will also be written into the writers
+// of ancestors. It does nothing for root logger.
+func (nl *namedLogger) SetAdditive(additive bool) {
+	if nl.ownsWriters() {
+		return
+	}
+
+	nl.multiWriter.routes.setAdditive(nl.name, additive)
+}
+
+// AddHook adds hooks which will be fired for events of this logger and its children.
+func (nl *namedLogger) AddHook(hooks ...Hook) {
+	if nl.multiWriter.hooks == nil {
+		return
+	}
+
+	if nl.isRootLogger() {
+		nl.multiWriter.hooks.add("", hooks...)
+	} else {
+		nl.multiWriter.hooks.add(nl.name, hooks...)
+	}
+}
+
+// SetSampler sets the sampler for events of this logger and its children, the events
+// dropped will be reported periodically. The sampler will be removed if it's nil.
+func (nl *namedLogger) SetSampler(sampler Sampler) {
+	if nl.multiWriter.hooks == nil {
+		return
+	}
+
+	var name string
+	if !nl.isRootLogger() {
+		name = nl.name
+	}
+
+	if sampler == nil {
+		nl.multiWriter.hooks.setSampler(name, nil)
+		return
+	}
+	nl.multiWriter.hooks.setSampler(name, newSamplingHook(nl.name, sampler,
+		samplingSummaryInterval, nl.multiWriter))
 }
 
 func (nl *namedLogger) CreateChild(name string) *namedLogger {
+	nl.locker.Lock()
+	defer nl.locker.Unlock()
+
 	child := newNamedLogger(name, nl, nl.multiWriter)
 	nl.children = append(nl.children, child)
-	child.level = nl.level
+	child.level = atomic.LoadInt32(&nl.level)
+	child.caller = atomic.LoadInt32(&nl.caller)
+	child.stackLevel = atomic.LoadInt32(&nl.stackLevel)
 
 	return child
 }
@@ -164,15 +321,108 @@ func (nl *namedLogger) isRootLogger() bool {
 	return nl.name == RootLoggerName
 }
 
+// attachedWriters returns the writers attached to this logger and its additivity.
+func (nl *namedLogger) attachedWriters() ([]Writer, bool) {
+	if nl.ownsWriters() {
+		return nl.multiWriter.load().writers, true
+	}
+
+	return nl.multiWriter.routes.lookup(nl.name)
+}
+
+// ownsWriters checks if the writers of multi writer belong to this logger.
+func (nl *namedLogger) ownsWriters() bool {
+	return nl.isRootLogger() || nl.multiWriter.routes == nil
+}
+
 func (nl *namedLogger) makeRecord(lvl Level, newRecord func() Record) Record {
 	var record Record
 
-	if nl.level > lvl {
-		record = newNoopRecord()
+	if nl.EffectiveLevel() > lvl {
+		// the record of fatal and panic is not written, but it still terminates
+		record = newNoopRecord(lvl)
 	} else {
 		record = newRecord()
+		if caller := atomic.LoadInt32(&nl.caller); caller&1 == 1 {
+			record = record.Caller(int(caller >> 1))
+		}
+		if lvl >= Level(atomic.LoadInt32(&nl.stackLevel)) {
+			record = record.Stack()
+		}
 	}
 
 	// append logger name
 	return record.Str(LoggerNameFieldKey, nl.name)
 }
+
+// boundLogger represents a child of named logger with pre-bound fields. It shares
+// level and writers with the named logger.
+type boundLogger struct {
+	origin     *namedLogger
+	realLogger ILogger
+}
+
+func newBoundLogger(origin *namedLogger, realLogger ILogger) *boundLogger {
+	return &boundLogger{
+		origin:     origin,
+		realLogger: realLogger,
+	}
+}
+
+func (bl *boundLogger) Name() string {
+	return bl.origin.Name()
+}
+
+func (bl *boundLogger) SetLevel(lvl Level) {
+	bl.origin.SetLevel(lvl)
+}
+
+func (bl *boundLogger) SetCaller(enabled bool, skip int) {
+	bl.origin.SetCaller(enabled, skip)
+}
+
+func (bl *boundLogger) SetStackLevel(lvl Level) {
+	bl.origin.SetStackLevel(lvl)
+}
+
+func (bl *boundLogger) With(fields func(Record)) ILogger {
+	return newBoundLogger(bl.origin, bl.realLogger.With(fields))
+}
+
+func (bl *boundLogger) Trace() Record {
+	return bl.origin.makeRecord(TraceLevel, bl.realLogger.Trace)
+}
+
+func (bl *boundLogger) Debug() Record {
+	return bl.origin.makeRecord(DebugLevel, bl.realLogger.Debug)
+}
+
+func (bl *boundLogger) Info() Record {
+	return bl.origin.makeRecord(InfoLevel, bl.realLogger.Info)
+}
+
+func (bl *boundLogger) Warn() Record {
+	return bl.origin.makeRecord(WarnLevel, bl.realLogger.Warn)
+}
+
+func (bl *boundLogger) Error() Record {
+	return bl.origin.makeRecord(ErrorLevel, bl.realLogger.Error)
+}
+
+func (bl *boundLogger) Fatal() Record {
+	return bl.origin.makeRecord(FatalLevel, bl.realLogger.Fatal)
+}
+
+func (bl *boundLogger) Panic() Record {
+	return bl.origin.makeRecord(PanicLevel, bl.realLogger.Panic)
+}
+
+func (bl *boundLogger) Level(lvl Level) Record {
+	return bl.origin.makeRecord(lvl, func() Record {
+		return bl.realLogger.Level(lvl)
+	})
+}
+
+func (bl *boundLogger) Event(e *LogEvent) {
+	bl.origin.Event(e)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"sync"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("named logger", func() {
+	var ctx *LoggerContext
+
+	ginkgo.BeforeEach(func() {
+		ctx = NewLoggerContext(NewClassicLogger)
+	})
+
+	ginkgo.It("inherit level", func() {
+		root := ctx.RealLogger(RootLoggerName)
+		db := ctx.RealLogger("github.com/acme/db")
+		sql := ctx.RealLogger("github.com/acme/db/sql")
+		http := ctx.RealLogger("github.com/acme/http")
+
+		root.SetLevel(InfoLevel)
+		Expect(sql.EffectiveLevel()).To(Equal(InfoLevel))
+		_, ok := sql.ExplicitLevel()
+		Expect(ok).To(BeFalse())
+
+		db.SetLevel(DebugLevel)
+		root.SetLevel(ErrorLevel)
+		Expect(db.EffectiveLevel()).To(Equal(DebugLevel))
+		Expect(sql.EffectiveLevel()).To(Equal(DebugLevel))
+		Expect(http.EffectiveLevel()).To(Equal(ErrorLevel))
+
+		// children created later inherit the level as well
+		Expect(ctx.RealLogger("github.com/acme/db/sql/tx").EffectiveLevel()).
+			To(Equal(DebugLevel))
+
+		db.ResetLevel()
+		Expect(db.EffectiveLevel()).To(Equal(ErrorLevel))
+		Expect(sql.EffectiveLevel()).To(Equal(ErrorLevel))
+
+		root.ResetLevel()
+		Expect(root.EffectiveLevel()).To(Equal(ErrorLevel))
+	})
+	ginkgo.It("set level concurrently", func() {
+		root := ctx.RealLogger(RootLoggerName)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				logger := ctx.RealLogger("github.com/acme/db")
+				for j := 0; j < 100; j++ {
+					root.SetLevel(Level(j % int(OffLevel)))
+					logger.Debug().Msg("concurrent")
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		root.SetLevel(WarnLevel)
+		Expect(ctx.RealLogger("github.com/acme/db").EffectiveLevel()).To(Equal(WarnLevel))
+	})
+	ginkgo.It("set caller and stack level concurrently", func() {
+		root := ctx.RealLogger(RootLoggerName)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				logger := ctx.RealLogger("github.com/acme/db")
+				for j := 0; j < 100; j++ {
+					root.SetCaller(j%2 == 0, j)
+					root.SetStackLevel(Level(j % int(OffLevel)))
+					logger.Info().Msg("concurrent")
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		root.SetCaller(true, 3)
+		caller := ctx.RealLogger("github.com/acme/db").caller
+		Expect(caller & 1).To(Equal(int32(1)))
+		Expect(caller >> 1).To(Equal(int32(3)))
+
+		root.SetStackLevel(ErrorLevel)
+		Expect(ctx.RealLogger("github.com/acme/db").stackLevel).To(Equal(int32(ErrorLevel)))
+	})
+})
@@ -0,0 +1,559 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MarshalDict encodes the fields added in given function into json object.
+func MarshalDict(dict func(r Record)) []byte {
+	e := NewLogEvent()
+	e.nested.writeDict(dict)
+	data := append([]byte(nil), e.nested.buf.Bytes()...)
+	e.Recycle()
+
+	return data
+}
+
+// MarshalObject encodes ObjectMarshaler into json object.
+func MarshalObject(obj ObjectMarshaler) []byte {
+	e := NewLogEvent()
+	e.nested.writeObject(obj)
+	data := append([]byte(nil), e.nested.buf.Bytes()...)
+	e.Recycle()
+
+	return data
+}
+
+// MarshalArray encodes ArrayMarshaler into json array.
+func MarshalArray(arr ArrayMarshaler) []byte {
+	e := NewLogEvent()
+	e.nested.writeArray(arr)
+	data := append([]byte(nil), e.nested.buf.Bytes()...)
+	e.Recycle()
+
+	return data
+}
+
+// nestedEncoder encodes nested objects and arrays into json.
+type nestedEncoder struct {
+	buf     *bytes.Buffer
+	scratch []byte
+	object  objectRecord
+	array   arrayEncoder
+}
+
+func newNestedEncoder() *nestedEncoder {
+	enc := &nestedEncoder{
+		buf:     new(bytes.Buffer),
+		scratch: make([]byte, 0, 64),
+	}
+	enc.object.enc = enc
+	enc.array.enc = enc
+
+	return enc
+}
+
+func (enc *nestedEncoder) reset() {
+	enc.buf.Reset()
+	enc.scratch = enc.scratch[:0]
+}
+
+// writeSeparator writes comma if this is not the first member or element.
+func (enc *nestedEncoder) writeSeparator() {
+	n := enc.buf.Len()
+	if n == 0 {
+		return
+	}
+	if last := enc.buf.Bytes()[n-1]; last != '{' && last != '[' {
+		enc.buf.WriteByte(',')
+	}
+}
+
+func (enc *nestedEncoder) writeKey(key string) {
+	enc.writeSeparator()
+	enc.writeString(key)
+	enc.buf.WriteByte(':')
+}
+
+func (enc *nestedEncoder) writeString(val string) {
+	enc.scratch = appendJsonString(enc.scratch[:0], val)
+	enc.buf.Write(enc.scratch)
+}
+
+func (enc *nestedEncoder) writeBool(val bool) {
+	enc.scratch = strconv.AppendBool(enc.scratch[:0], val)
+	enc.buf.Write(enc.scratch)
+}
+
+func (enc *nestedEncoder) writeInt(val int64) {
+	enc.scratch = strconv.AppendInt(enc.scratch[:0], val, 10)
+	enc.buf.Write(enc.scratch)
+}
+
+func (enc *nestedEncoder) writeUint(val uint64) {
+	enc.scratch = strconv.AppendUint(enc.scratch[:0], val, 10)
+	enc.buf.Write(enc.scratch)
+}
+
+func (enc *nestedEncoder) writeFloat(val float64, bitSize int) {
+	enc.scratch = appendJsonFloat(enc.scratch[:0], val, bitSize)
+	enc.buf.Write(enc.scratch)
+}
+
+func (enc *nestedEncoder) writeTime(val time.Time) {
+	enc.buf.WriteByte('"')
+	enc.scratch, _ = appendFormat(enc.scratch[:0], val, TimeFormatRFC3339)
+	enc.buf.Write(enc.scratch)
+	enc.buf.WriteByte('"')
+}
+
+func (enc *nestedEncoder) writeErr(err error) {
+	if err == nil {
+		enc.buf.WriteString("null")
+		return
+	}
+	enc.writeString(err.Error())
+}
+
+// writeError writes error as json object with message, type, details and causes.
+func (enc *nestedEncoder) writeError(err error, depth int) {
+	if err == nil {
+		enc.buf.WriteString("null")
+		return
+	}
+
+	enc.buf.WriteByte('{')
+	enc.writeKey(errorMessageKey)
+	enc.writeString(err.Error())
+	enc.writeKey(errorTypeKey)
+	enc.writeString(errorType(err))
+	if m, ok := err.(ErrorMarshaler); ok {
+		enc.writeKey(errorDetailsKey)
+		enc.writeDict(m.MarshalLogError)
+	}
+	if depth < maxErrorDepth && hasErrorCauses(err) {
+		enc.writeKey(errorCausesKey)
+		enc.buf.WriteByte('[')
+		enc.writeErrorCauses(err, depth+1)
+		enc.buf.WriteByte(']')
+	}
+	enc.buf.WriteByte('}')
+}
+
+// writeErrorCauses writes the causes of error as array elements.
+func (enc *nestedEncoder) writeErrorCauses(err error, depth int) {
+	walkErrorCauses(err, func(cause error) {
+		enc.writeSeparator()
+		enc.writeError(cause, depth)
+	})
+}
+
+// writeSlice writes array with n elements, the element will be written in f.
+func (enc *nestedEncoder) writeSlice(n int, f func(i int)) {
+	enc.buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		enc.writeSeparator()
+		f(i)
+	}
+	enc.buf.WriteByte(']')
+}
+
+func (enc *nestedEncoder) writeDict(dict func(r Record)) {
+	enc.buf.WriteByte('{')
+	dict(&enc.object)
+	enc.buf.WriteByte('}')
+}
+
+func (enc *nestedEncoder) writeObject(obj ObjectMarshaler) {
+	enc.buf.WriteByte('{')
+	obj.MarshalLogObject(&enc.object)
+	enc.buf.WriteByte('}')
+}
+
+func (enc *nestedEncoder) writeArray(arr ArrayMarshaler) {
+	enc.buf.WriteByte('[')
+	arr.MarshalLogArray(&enc.array)
+	enc.buf.WriteByte(']')
+}
+
+func (enc *nestedEncoder) writeAny(val interface{}) {
+	switch v := val.(type) {
+	case nil:
+		enc.buf.WriteString("null")
+	case LogValuer:
+		enc.writeAny(ResolveLogValue(v))
+	case string:
+		enc.writeString(v)
+	case bool:
+		enc.writeBool(v)
+	case int:
+		enc.writeInt(int64(v))
+	case int64:
+		enc.writeInt(v)
+	case uint64:
+		enc.writeUint(v)
+	case float64:
+		enc.writeFloat(v, 64)
+	case time.Time:
+		enc.writeTime(v)
+	case time.Duration:
+		enc.writeInt(v.Nanoseconds())
+	case error:
+		enc.writeErr(v)
+	case ObjectMarshaler:
+		enc.writeObject(v)
+	case ArrayMarshaler:
+		enc.writeArray(v)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			enc.writeString(fmt.Sprintf("marshaling error: %v", err))
+			return
+		}
+		enc.buf.Write(data)
+	}
+}
+
+// objectRecord is a Record which encodes fields as members of json object.
+type objectRecord struct {
+	enc *nestedEncoder
+}
+
+func (r *objectRecord) Str(key, val string) Record {
+	r.enc.writeKey(key)
+	r.enc.writeString(val)
+	return r
+}
+
+func (r *objectRecord) Strs(key string, val []string) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeString(val[i]) })
+	return r
+}
+
+func (r *objectRecord) Bytes(key string, val []byte) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeUint(uint64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Err(err error) Record {
+	EncodeError(r, err)
+	return r
+}
+
+func (r *objectRecord) Errs(key string, errs []error) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(errs), func(i int) { r.enc.writeError(errs[i], 0) })
+	return r
+}
+
+func (r *objectRecord) Bool(key string, val bool) Record {
+	r.enc.writeKey(key)
+	r.enc.writeBool(val)
+	return r
+}
+
+func (r *objectRecord) Bools(key string, val []bool) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeBool(val[i]) })
+	return r
+}
+
+func (r *objectRecord) Int(key string, val int) Record {
+	return r.Int64(key, int64(val))
+}
+
+func (r *objectRecord) Ints(key string, val []int) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeInt(int64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Int8(key string, val int8) Record {
+	return r.Int64(key, int64(val))
+}
+
+func (r *objectRecord) Ints8(key string, val []int8) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeInt(int64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Int16(key string, val int16) Record {
+	return r.Int64(key, int64(val))
+}
+
+func (r *objectRecord) Ints16(key string, val []int16) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeInt(int64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Int32(key string, val int32) Record {
+	return r.Int64(key, int64(val))
+}
+
+func (r *objectRecord) Ints32(key string, val []int32) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeInt(int64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Int64(key string, val int64) Record {
+	r.enc.writeKey(key)
+	r.enc.writeInt(val)
+	return r
+}
+
+func (r *objectRecord) Ints64(key string, val []int64) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeInt(val[i]) })
+	return r
+}
+
+func (r *objectRecord) Uint(key string, val uint) Record {
+	return r.Uint64(key, uint64(val))
+}
+
+func (r *objectRecord) Uints(key string, val []uint) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeUint(uint64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Uint8(key string, val uint8) Record {
+	return r.Uint64(key, uint64(val))
+}
+
+func (r *objectRecord) Uints8(key string, val []uint8) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeUint(uint64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Uint16(key string, val uint16) Record {
+	return r.Uint64(key, uint64(val))
+}
+
+func (r *objectRecord) Uints16(key string, val []uint16) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeUint(uint64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Uint32(key string, val uint32) Record {
+	return r.Uint64(key, uint64(val))
+}
+
+func (r *objectRecord) Uints32(key string, val []uint32) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeUint(uint64(val[i])) })
+	return r
+}
+
+func (r *objectRecord) Uint64(key string, val uint64) Record {
+	r.enc.writeKey(key)
+	r.enc.writeUint(val)
+	return r
+}
+
+func (r *objectRecord) Uints64(key string, val []uint64) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeUint(val[i]) })
+	return r
+}
+
+func (r *objectRecord) Float32(key string, val float32) Record {
+	r.enc.writeKey(key)
+	r.enc.writeFloat(float64(val), 32)
+	return r
+}
+
+func (r *objectRecord) Floats32(key string, val []float32) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeFloat(float64(val[i]), 32) })
+	return r
+}
+
+func (r *objectRecord) Float64(key string, val float64) Record {
+	r.enc.writeKey(key)
+	r.enc.writeFloat(val, 64)
+	return r
+}
+
+func (r *objectRecord) Floats64(key string, val []float64) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeFloat(val[i], 64) })
+	return r
+}
+
+func (r *objectRecord) Time(key string, val time.Time) Record {
+	r.enc.writeKey(key)
+	r.enc.writeTime(val)
+	return r
+}
+
+func (r *objectRecord) Times(key string, val []time.Time) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeTime(val[i]) })
+	return r
+}
+
+func (r *objectRecord) Dur(key string, val time.Duration) Record {
+	r.enc.writeKey(key)
+	r.enc.writeInt(val.Nanoseconds())
+	return r
+}
+
+func (r *objectRecord) Durs(key string, val []time.Duration) Record {
+	r.enc.writeKey(key)
+	r.enc.writeSlice(len(val), func(i int) { r.enc.writeInt(val[i].Nanoseconds()) })
+	return r
+}
+
+func (r *objectRecord) Any(key string, val interface{}) Record {
+	r.enc.writeKey(key)
+	r.enc.writeAny(val)
+	return r
+}
+
+func (r *objectRecord) Dict(key string, dict func(r Record)) Record {
+	r.enc.writeKey(key)
+	r.enc.writeDict(dict)
+	return r
+}
+
+func (r *objectRecord) Object(key string, obj ObjectMarshaler) Record {
+	r.enc.writeKey(key)
+	r.enc.writeObject(obj)
+	return r
+}
+
+func (r *objectRecord) Array(key string, arr ArrayMarshaler) Record {
+	r.enc.writeKey(key)
+	r.enc.writeArray(arr)
+	return r
+}
+
+func (r *objectRecord) Ctx(ctx context.Context) Record {
+	ExtractContext(ctx, r)
+	return r
+}
+
+func (r *objectRecord) Caller(_ int) Record {
+	return r
+}
+
+func (r *objectRecord) Stack() Record {
+	return r
+}
+
+func (r *objectRecord) Msge() {
+}
+
+func (r *objectRecord) Msg(_ string) {
+}
+
+func (r *objectRecord) Msgf(_ string, _ ...interface{}) {
+}
+
+// arrayEncoder is an ArrayEncoder which encodes elements of json array.
+type arrayEncoder struct {
+	enc *nestedEncoder
+}
+
+func (a *arrayEncoder) Str(val string) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeString(val)
+	return a
+}
+
+func (a *arrayEncoder) Bool(val bool) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeBool(val)
+	return a
+}
+
+func (a *arrayEncoder) Int(val int) ArrayEncoder {
+	return a.Int64(int64(val))
+}
+
+func (a *arrayEncoder) Int64(val int64) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeInt(val)
+	return a
+}
+
+func (a *arrayEncoder) Uint64(val uint64) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeUint(val)
+	return a
+}
+
+func (a *arrayEncoder) Float64(val float64) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeFloat(val, 64)
+	return a
+}
+
+func (a *arrayEncoder) Time(val time.Time) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeTime(val)
+	return a
+}
+
+func (a *arrayEncoder) Dur(val time.Duration) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeInt(val.Nanoseconds())
+	return a
+}
+
+func (a *arrayEncoder) Err(err error) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeError(err, 0)
+	return a
+}
+
+func (a *arrayEncoder) Any(val interface{}) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeAny(val)
+	return a
+}
+
+func (a *arrayEncoder) Dict(dict func(r Record)) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeDict(dict)
+	return a
+}
+
+func (a *arrayEncoder) Object(obj ObjectMarshaler) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeObject(obj)
+	return a
+}
+
+func (a *arrayEncoder) Array(arr ArrayMarshaler) ArrayEncoder {
+	a.enc.writeSeparator()
+	a.enc.writeArray(arr)
+	return a
+}
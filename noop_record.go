@@ -15,6 +15,8 @@
 package lork
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -27,11 +29,17 @@ var (
 	}
 )
 
+// noopRecord is a record which writes nothing. It still terminates if the level is
+// FatalLevel or PanicLevel.
 type noopRecord struct {
+	level Level
 }
 
-func newNoopRecord() *noopRecord {
-	return noopRecordPool.Get().(*noopRecord)
+func newNoopRecord(lvl Level) *noopRecord {
+	r := noopRecordPool.Get().(*noopRecord)
+	r.level = lvl
+
+	return r
 }
 
 func (r *noopRecord) Str(_, _ string) Record {
@@ -182,14 +190,48 @@ func (r *noopRecord) Any(_ string, _ interface{}) Record {
 	return r
 }
 
+func (r *noopRecord) Dict(_ string, _ func(r Record)) Record {
+	return r
+}
+
+func (r *noopRecord) Object(_ string, _ ObjectMarshaler) Record {
+	return r
+}
+
+func (r *noopRecord) Array(_ string, _ ArrayMarshaler) Record {
+	return r
+}
+
+func (r *noopRecord) Ctx(_ context.Context) Record {
+	return r
+}
+
+func (r *noopRecord) Caller(_ int) Record {
+	return r
+}
+
+func (r *noopRecord) Stack() Record {
+	return r
+}
+
 func (r *noopRecord) Msge() {
-	noopRecordPool.Put(r)
+	r.terminate("")
 }
 
-func (r *noopRecord) Msg(_ string) {
-	noopRecordPool.Put(r)
+func (r *noopRecord) Msg(msg string) {
+	r.terminate(msg)
+}
+
+func (r *noopRecord) Msgf(format string, v ...interface{}) {
+	if r.level == FatalLevel || r.level == PanicLevel {
+		r.terminate(fmt.Sprintf(format, v...))
+	} else {
+		r.terminate("")
+	}
 }
 
-func (r *noopRecord) Msgf(_ string, _ ...interface{}) {
+func (r *noopRecord) terminate(msg string) {
+	lvl := r.level
 	noopRecordPool.Put(r)
+	Terminate(lvl, msg)
 }
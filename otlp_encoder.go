@@ -0,0 +1,329 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/buger/jsonparser"
+)
+
+// otlpSeverityNumbers maps lork level to severity number of OpenTelemetry log data model.
+var otlpSeverityNumbers = [...]int{
+	TraceLevel: 1,
+	DebugLevel: 5,
+	InfoLevel:  9,
+	WarnLevel:  13,
+	ErrorLevel: 17,
+	FatalLevel: 21,
+	PanicLevel: 22,
+}
+
+// otlpAttributeKeys maps the fields of lork to semantic conventions of OpenTelemetry.
+var otlpAttributeKeys = map[string]string{
+	ErrorFieldKey:     "exception.message",
+	ErrorTypeFieldKey: "exception.type",
+}
+
+// OtlpEncoderOption represents available options for otlp encoder.
+type OtlpEncoderOption struct {
+	// TraceIdKey is the key of field which carries trace id, default is TraceIdFieldKey.
+	TraceIdKey string
+	// SpanIdKey is the key of field which carries span id, default is SpanIdFieldKey.
+	SpanIdKey string
+	// Resource is the attributes of resource which produces logs, service.name will be
+	// unknown_service:<process name> if not set.
+	Resource map[string]string
+}
+
+// otlpEncoder encodes logging event into OTLP/JSON logs data.
+type otlpEncoder struct {
+	opts    *OtlpEncoderOption
+	locker  sync.Mutex
+	prefix  []byte
+	buf     []byte
+	stack   []byte
+	traceId []byte
+	spanId  []byte
+}
+
+// NewOtlpEncoder creates a new instance of encoder to encode data to OTLP/JSON logs
+// data of OpenTelemetry log data model, one log record with its resource and scope per
+// line. The logger name is written as the name of scope, the fields of event are
+// written as attributes, and the fields carrying trace id and span id (hex string)
+// are written as traceId and spanId of the record.
+func NewOtlpEncoder(options ...func(*OtlpEncoderOption)) Encoder {
+	opts := &OtlpEncoderOption{
+		TraceIdKey: TraceIdFieldKey,
+		SpanIdKey:  SpanIdFieldKey,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	resource := map[string]string{
+		"service.name": "unknown_service:" + filepath.Base(os.Args[0]),
+	}
+	for k, v := range opts.Resource {
+		resource[k] = v
+	}
+	keys := make([]string, 0, len(resource))
+	for k := range resource {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var prefix []byte
+	prefix = append(prefix, `{"resourceLogs":[{"resource":{"attributes":[`...)
+	for i, k := range keys {
+		if i > 0 {
+			prefix = append(prefix, ',')
+		}
+		prefix = append(prefix, `{"key":`...)
+		prefix = appendJsonString(prefix, k)
+		prefix = append(prefix, `,"value":{"stringValue":`...)
+		prefix = appendJsonString(prefix, resource[k])
+		prefix = append(prefix, '}', '}')
+	}
+	prefix = append(prefix, `]},"scopeLogs":[{"scope":{`...)
+
+	return &otlpEncoder{
+		opts:   opts,
+		prefix: prefix,
+	}
+}
+
+func init() {
+	RegisterEncoder("otlp", newOtlpEncoderFromConfig)
+}
+
+type otlpEncoderConfig struct {
+	TraceIdKey string            `json:"trace_id_key"`
+	SpanIdKey  string            `json:"span_id_key"`
+	Resource   map[string]string `json:"resource"`
+}
+
+func newOtlpEncoderFromConfig(d OptionDecoder) (Encoder, error) {
+	var c otlpEncoderConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	return NewOtlpEncoder(func(o *OtlpEncoderOption) {
+		setIfNotEmpty(&o.TraceIdKey, c.TraceIdKey)
+		setIfNotEmpty(&o.SpanIdKey, c.SpanIdKey)
+		o.Resource = c.Resource
+	}), nil
+}
+
+func (oe *otlpEncoder) Encode(e *LogEvent) ([]byte, error) {
+	oe.locker.Lock()
+	defer oe.locker.Unlock()
+
+	oe.buf = append(oe.buf[:0], oe.prefix...)
+	if logger := e.LoggerName(); len(logger) != 0 {
+		oe.buf = append(oe.buf, `"name":"`...)
+		oe.appendString(logger)
+		oe.buf = append(oe.buf, '"')
+	}
+	oe.buf = append(oe.buf, `},"logRecords":[{"timeUnixNano":"`...)
+	oe.buf = strconv.AppendInt(oe.buf, e.Timestamp(), 10)
+	oe.buf = append(oe.buf, `","severityNumber":`...)
+	lvl := e.LevelInt()
+	if lvl >= TraceLevel && int(lvl) < len(otlpSeverityNumbers) {
+		oe.buf = strconv.AppendInt(oe.buf, int64(otlpSeverityNumbers[lvl]), 10)
+	} else {
+		oe.buf = append(oe.buf, '0')
+	}
+	oe.buf = append(oe.buf, `,"severityText":"`...)
+	oe.buf = append(oe.buf, e.Level()...)
+	oe.buf = append(oe.buf, `","body":{"stringValue":"`...)
+	oe.appendString(e.Message())
+	oe.buf = append(oe.buf, `"},"attributes":[`...)
+	start := len(oe.buf)
+
+	if caller := e.Caller(); len(caller) != 0 {
+		if i := bytes.LastIndexByte(caller, ':'); i > 0 {
+			oe.writeRawAttribute("code.filepath", caller[:i])
+			oe.writeAttribute("code.lineno", caller[i+1:], false)
+		} else {
+			oe.writeRawAttribute("code.filepath", caller)
+		}
+		if fn := e.CallerFunc(); len(fn) != 0 {
+			oe.writeRawAttribute("code.function", fn)
+		}
+	}
+
+	oe.traceId = oe.traceId[:0]
+	oe.spanId = oe.spanId[:0]
+	_ = e.Fields(func(k, v []byte, isString bool) error {
+		if isString && string(k) == oe.opts.TraceIdKey && isHexId(v, 32) {
+			oe.traceId = append(oe.traceId, v...)
+			return nil
+		}
+		if isString && string(k) == oe.opts.SpanIdKey && isHexId(v, 16) {
+			oe.spanId = append(oe.spanId, v...)
+			return nil
+		}
+		if name, ok := otlpAttributeKeys[string(k)]; ok {
+			oe.writeAttribute(name, v, isString)
+		} else {
+			oe.writeKeyValue(k, v, isString)
+		}
+		return nil
+	})
+
+	if stack := e.Stack(); len(stack) != 0 {
+		oe.stack = appendStackText(oe.stack[:0], stack)
+		oe.writeAttribute("exception.stacktrace", oe.stack, true)
+	}
+
+	if len(oe.buf) == start {
+		// no attributes, remove the key
+		oe.buf = oe.buf[:start-len(`,"attributes":[`)]
+	} else {
+		oe.buf[len(oe.buf)-1] = ']'
+	}
+	if len(oe.traceId) != 0 {
+		oe.buf = append(oe.buf, `,"traceId":"`...)
+		oe.buf = append(oe.buf, oe.traceId...)
+		oe.buf = append(oe.buf, '"')
+	}
+	if len(oe.spanId) != 0 {
+		oe.buf = append(oe.buf, `,"spanId":"`...)
+		oe.buf = append(oe.buf, oe.spanId...)
+		oe.buf = append(oe.buf, '"')
+	}
+	oe.buf = append(oe.buf, `}]}]}]}`...)
+	oe.buf = append(oe.buf, '\n')
+
+	return oe.buf, nil
+}
+
+// appendString appends unescaped value of event as escaped json string content.
+func (oe *otlpEncoder) appendString(value []byte) {
+	if needsJsonEscape(value) {
+		oe.buf = appendEscapedString(oe.buf, string(value))
+	} else {
+		oe.buf = append(oe.buf, value...)
+	}
+}
+
+// writeRawAttribute writes a string attribute with unescaped value of event.
+func (oe *otlpEncoder) writeRawAttribute(key string, value []byte) {
+	oe.buf = append(oe.buf, `{"key":"`...)
+	oe.buf = append(oe.buf, key...)
+	oe.buf = append(oe.buf, `","value":{"stringValue":"`...)
+	oe.appendString(value)
+	oe.buf = append(oe.buf, '"', '}', '}', ',')
+}
+
+func (oe *otlpEncoder) writeAttribute(key string, value []byte, isString bool) {
+	oe.buf = append(oe.buf, `{"key":"`...)
+	oe.buf = append(oe.buf, key...)
+	oe.writeValueOfKey(value, isString)
+}
+
+// writeKeyValue writes a KeyValue of OTLP, the key is escaped json string.
+func (oe *otlpEncoder) writeKeyValue(key, value []byte, isString bool) {
+	oe.buf = append(oe.buf, `{"key":"`...)
+	oe.buf = append(oe.buf, key...)
+	oe.writeValueOfKey(value, isString)
+}
+
+func (oe *otlpEncoder) writeValueOfKey(value []byte, isString bool) {
+	oe.buf = append(oe.buf, `","value":`...)
+	oe.writeAnyValue(value, isString)
+	oe.buf = append(oe.buf, '}', ',')
+}
+
+// writeAnyValue writes json value as AnyValue of OTLP, objects are written as
+// kvlistValue and arrays are written as arrayValue.
+func (oe *otlpEncoder) writeAnyValue(v []byte, isString bool) {
+	if isString {
+		oe.buf = append(oe.buf, `{"stringValue":"`...)
+		oe.buf = append(oe.buf, v...)
+		oe.buf = append(oe.buf, '"', '}')
+		return
+	}
+
+	switch {
+	case isJsonObject(v):
+		oe.buf = append(oe.buf, `{"kvlistValue":{"values":[`...)
+		start := len(oe.buf)
+		_ = jsonparser.ObjectEach(v, func(k []byte, mv []byte,
+			dataType jsonparser.ValueType, _ int) error {
+			oe.writeKeyValue(k, mv, dataType == jsonparser.String)
+			return nil
+		})
+		oe.closeValues(start)
+
+	case isJsonArray(v):
+		oe.buf = append(oe.buf, `{"arrayValue":{"values":[`...)
+		start := len(oe.buf)
+		_, _ = jsonparser.ArrayEach(v, func(av []byte, dataType jsonparser.ValueType,
+			_ int, _ error) {
+			oe.writeAnyValue(av, dataType == jsonparser.String)
+			oe.buf = append(oe.buf, ',')
+		})
+		oe.closeValues(start)
+
+	case bytes.Equal(v, []byte("true")) || bytes.Equal(v, []byte("false")):
+		oe.buf = append(oe.buf, `{"boolValue":`...)
+		oe.buf = append(oe.buf, v...)
+		oe.buf = append(oe.buf, '}')
+
+	case bytes.Equal(v, []byte("null")):
+		oe.buf = append(oe.buf, '{', '}')
+
+	case bytes.ContainsAny(v, ".eE"):
+		oe.buf = append(oe.buf, `{"doubleValue":`...)
+		oe.buf = append(oe.buf, v...)
+		oe.buf = append(oe.buf, '}')
+
+	default:
+		// int64 is encoded as string in OTLP/JSON
+		oe.buf = append(oe.buf, `{"intValue":"`...)
+		oe.buf = append(oe.buf, v...)
+		oe.buf = append(oe.buf, '"', '}')
+	}
+}
+
+// closeValues closes the values started at start, the trailing comma is removed.
+func (oe *otlpEncoder) closeValues(start int) {
+	if len(oe.buf) > start {
+		oe.buf = oe.buf[:len(oe.buf)-1]
+	}
+	oe.buf = append(oe.buf, ']', '}', '}')
+}
+
+// isHexId checks if the value is a hex encoded id with given length.
+func isHexId(v []byte, size int) bool {
+	if len(v) != size {
+		return false
+	}
+	for _, c := range v {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+
+	return true
+}
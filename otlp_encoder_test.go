@@ -0,0 +1,121 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("otlp encoder", func() {
+	event := MakeEvent([]byte(`{"level":"WARN","time":"2019-12-27T10:40:14.465199844+08:00",` +
+		`"logger_name":"github.com/lork","caller":"/go/src/app/main.go:42","func":"main.main",` +
+		`"message":"hello \"lork\"","str":"a\tb","int":-12,"float":1.5,"ok":true,"nil":null,` +
+		`"user":{"name":"lork","tags":["a",1]},"empty":[],"error":"read failed",` +
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7",` +
+		`"stack":[{"func":"main.work","file":"/go/src/app/main.go","line":12}]}`))
+
+	ginkgo.It("encode", func() {
+		out, err := NewOtlpEncoder(func(o *OtlpEncoderOption) {
+			o.Resource = map[string]string{"service.name": "app", "host.name": "h1"}
+		}).Encode(event)
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(Equal(`{"resourceLogs":[{"resource":{"attributes":[` +
+			`{"key":"host.name","value":{"stringValue":"h1"}},` +
+			`{"key":"service.name","value":{"stringValue":"app"}}]},` +
+			`"scopeLogs":[{"scope":{"name":"github.com/lork"},"logRecords":[{"timeUnixNano":"` +
+			strconv.FormatInt(event.Timestamp(), 10) + `","severityNumber":13,` +
+			`"severityText":"WARN","body":{"stringValue":"hello \"lork\""},"attributes":[` +
+			`{"key":"code.filepath","value":{"stringValue":"/go/src/app/main.go"}},` +
+			`{"key":"code.lineno","value":{"intValue":"42"}},` +
+			`{"key":"code.function","value":{"stringValue":"main.main"}},` +
+			`{"key":"str","value":{"stringValue":"a\tb"}},` +
+			`{"key":"int","value":{"intValue":"-12"}},` +
+			`{"key":"float","value":{"doubleValue":1.5}},` +
+			`{"key":"ok","value":{"boolValue":true}},` +
+			`{"key":"nil","value":{}},` +
+			`{"key":"user","value":{"kvlistValue":{"values":[` +
+			`{"key":"name","value":{"stringValue":"lork"}},` +
+			`{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"a"},{"intValue":"1"}]}}}]}}},` +
+			`{"key":"empty","value":{"arrayValue":{"values":[]}}},` +
+			`{"key":"exception.message","value":{"stringValue":"read failed"}},` +
+			`{"key":"exception.stacktrace","value":{"stringValue":"main.work\n\t/go/src/app/main.go:12\n"}}],` +
+			`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7"}]}]}]}` + "\n"))
+		Expect(json.Valid(out)).To(BeTrue())
+	})
+	ginkgo.It("escape logger and caller", func() {
+		e := NewLogEvent()
+		e.appendTimestamp()
+		e.appendLevel(InfoLevel)
+		e.appendString(LoggerNameFieldKey, `a"b`)
+		e.appendString(CallerFieldKey, `C:\app\main.go:7`)
+		e.appendString(FunctionFieldKey, `main."f"`)
+		e.appendMessage("hello")
+		out, err := NewOtlpEncoder().Encode(e)
+		Expect(err).To(BeNil())
+		Expect(json.Valid(out)).To(BeTrue())
+		Expect(string(out)).To(ContainSubstring(`"scope":{"name":"a\"b"}`))
+		Expect(string(out)).To(ContainSubstring(`"attributes":[` +
+			`{"key":"code.filepath","value":{"stringValue":"C:\\app\\main.go"}},` +
+			`{"key":"code.lineno","value":{"intValue":"7"}},` +
+			`{"key":"code.function","value":{"stringValue":"main.\"f\""}}]`))
+
+		var data struct {
+			ResourceLogs []struct {
+				Resource struct {
+					Attributes []struct {
+						Key string `json:"key"`
+					} `json:"attributes"`
+				} `json:"resource"`
+				ScopeLogs []struct {
+					LogRecords []json.RawMessage `json:"logRecords"`
+				} `json:"scopeLogs"`
+			} `json:"resourceLogs"`
+		}
+		Expect(json.Unmarshal(out, &data)).To(BeNil())
+		Expect(data.ResourceLogs).To(HaveLen(1))
+		Expect(data.ResourceLogs[0].Resource.Attributes[0].Key).To(Equal("service.name"))
+		Expect(data.ResourceLogs[0].ScopeLogs[0].LogRecords).To(HaveLen(1))
+	})
+	ginkgo.It("encode without attributes", func() {
+		out, err := NewOtlpEncoder().Encode(MakeEvent([]byte(
+			`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00","message":"hello",` +
+				`"trace_id":"invalid"}`)))
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"severityNumber":9,"severityText":"INFO",` +
+			`"body":{"stringValue":"hello"},"attributes":[` +
+			`{"key":"trace_id","value":{"stringValue":"invalid"}}]}]}]}]}` + "\n"))
+
+		out, err = NewOtlpEncoder().Encode(MakeEvent([]byte(
+			`{"level":"ERROR","time":"2019-12-27T10:40:14.465199844+08:00","message":"hello"}`)))
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"severityNumber":17,"severityText":"ERROR",` +
+			`"body":{"stringValue":"hello"}}]}]}]}` + "\n"))
+	})
+	ginkgo.It("custom trace keys", func() {
+		out, err := NewOtlpEncoder(func(o *OtlpEncoderOption) {
+			o.TraceIdKey = "traceId"
+			o.SpanIdKey = "spanId"
+		}).Encode(MakeEvent([]byte(
+			`{"level":"INFO","time":"2019-12-27T10:40:14.465199844+08:00","message":"hello",` +
+				`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7"}`)))
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"body":{"stringValue":"hello"},` +
+			`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7"}]}]}]}` + "\n"))
+	})
+})
@@ -16,8 +16,11 @@ package lork
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
 	"sync"
+
+	"github.com/buger/jsonparser"
 )
 
 const (
@@ -80,10 +83,48 @@ func NewPatternEncoder(options ...func(*PatternEncoderOption)) Encoder {
 		pattern = opts.Pattern
 	}
 
+	converter, err := compilePattern(pattern, opts.Converters)
+	if err != nil {
+		ReportfExit("%v", err)
+	}
+
+	return &patternEncoder{
+		buf:       new(bytes.Buffer),
+		converter: converter,
+	}
+}
+
+func init() {
+	RegisterEncoder("pattern", newPatternEncoderFromConfig)
+}
+
+type patternEncoderConfig struct {
+	Pattern string `json:"pattern"`
+}
+
+func newPatternEncoderFromConfig(d OptionDecoder) (Encoder, error) {
+	var c patternEncoderConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if len(c.Pattern) != 0 {
+		if _, err := compilePattern(c.Pattern, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewPatternEncoder(func(o *PatternEncoderOption) {
+		o.Pattern = c.Pattern
+	}), nil
+}
+
+// compilePattern parses and compiles pattern into converter with builtin converters
+// and the extra ones.
+func compilePattern(pattern string, extra map[string]NewConverter) (Converter, error) {
 	patternParser := newPatternParser(pattern)
 	node, err := patternParser.Parse()
 	if err != nil {
-		ReportfExit("parse pattern error, %v", err)
+		return nil, fmt.Errorf("parse pattern error, %v", err)
 	}
 
 	converters := map[string]NewConverter{
@@ -93,19 +134,19 @@ func NewPatternEncoder(options ...func(*PatternEncoderOption)) Encoder {
 		"logger":  newLoggerNameConverter,
 		"message": newMessageConverter,
 		"fields":  newFieldsConverter,
+		"caller":  newCallerConverter,
+		"stack":   newStackConverter,
+		"error":   newErrorConverter,
 	}
-	for k, c := range opts.Converters {
+	for k, c := range extra {
 		converters[k] = c
 	}
 	converter, err := newPatternCompiler(node, converters).Compile()
 	if err != nil {
-		ReportfExit("compile pattern error, %v", err)
+		return nil, fmt.Errorf("compile pattern error, %v", err)
 	}
 
-	return &patternEncoder{
-		buf:       new(bytes.Buffer),
-		converter: converter,
-	}
+	return converter, nil
 }
 
 func (pe *patternEncoder) Encode(e *LogEvent) (data []byte, err error) {
@@ -397,13 +438,15 @@ func (mc *messageConverter) Convert(origin interface{}, buf *bytes.Buffer) {
 }
 
 type fieldsConverter struct {
-	next Converter
-	buf  *bytes.Buffer
+	next    Converter
+	buf     *bytes.Buffer
+	scratch []byte
 }
 
 func newFieldsConverter() Converter {
 	return &fieldsConverter{
-		buf: new(bytes.Buffer),
+		buf:     new(bytes.Buffer),
+		scratch: make([]byte, 0, 128),
 	}
 }
 
@@ -427,10 +470,15 @@ func (fc *fieldsConverter) Convert(origin interface{}, buf *bytes.Buffer) {
 		return
 	}
 
-	_ = e.Fields(func(k, v []byte, isString bool) error {
+	_ = e.WalkFields(func(k, v []byte, isString bool) error {
 		buf.Write(k)
 		buf.WriteString("=")
-		buf.Write(v)
+		if isString {
+			// string values are written as they are without json escaping
+			writeUnescaped(buf, v, fc.scratch)
+		} else {
+			buf.Write(v)
+		}
 		buf.WriteByte(' ')
 		return nil
 	})
@@ -438,3 +486,195 @@ func (fc *fieldsConverter) Convert(origin interface{}, buf *bytes.Buffer) {
 	// remove last space
 	buf.Truncate(buf.Len() - 1)
 }
+
+type callerConverter struct {
+	next Converter
+	opt  string
+}
+
+func newCallerConverter() Converter {
+	return &callerConverter{
+		opt: "short",
+	}
+}
+
+func (cc *callerConverter) AttachNext(next Converter) {
+	cc.next = next
+}
+
+func (cc *callerConverter) Next() Converter {
+	return cc.next
+}
+
+func (cc *callerConverter) AttachChild(_ Converter) {
+}
+
+func (cc *callerConverter) AttachOptions(opts []string) {
+	if len(opts) != 0 && len(opts[0]) != 0 {
+		cc.opt = opts[0]
+	}
+}
+
+func (cc *callerConverter) Convert(origin interface{}, buf *bytes.Buffer) {
+	e, ok := origin.(*LogEvent)
+	if !ok {
+		buf.WriteByte('-')
+		return
+	}
+
+	var caller []byte
+	switch cc.opt {
+	case "full":
+		caller = e.Caller()
+	case "func":
+		caller = e.CallerFunc()
+	default:
+		caller = cc.shorten(e.Caller())
+	}
+
+	if len(caller) == 0 {
+		buf.WriteByte('-')
+		return
+	}
+
+	buf.Write(caller)
+}
+
+// shorten keeps the last directory and file name of caller, e.g. lork/event.go:42.
+func (cc *callerConverter) shorten(caller []byte) []byte {
+	index := bytes.LastIndexByte(caller, '/')
+	if index <= 0 {
+		return caller
+	}
+	if i := bytes.LastIndexByte(caller[:index], '/'); i >= 0 {
+		return caller[i+1:]
+	}
+
+	return caller
+}
+
+type stackConverter struct {
+	next    Converter
+	scratch []byte
+}
+
+func newStackConverter() Converter {
+	return &stackConverter{
+		scratch: make([]byte, 0, 128),
+	}
+}
+
+func (sc *stackConverter) AttachNext(next Converter) {
+	sc.next = next
+}
+
+func (sc *stackConverter) Next() Converter {
+	return sc.next
+}
+
+func (sc *stackConverter) AttachChild(_ Converter) {
+}
+
+func (sc *stackConverter) AttachOptions(_ []string) {
+}
+
+// Convert writes each frame of stack in new lines, the function name is indented
+// with one tab and the file with line is indented with two tabs.
+func (sc *stackConverter) Convert(origin interface{}, buf *bytes.Buffer) {
+	e, ok := origin.(*LogEvent)
+	if !ok {
+		return
+	}
+
+	stack := e.Stack()
+	if len(stack) == 0 {
+		return
+	}
+
+	_, _ = jsonparser.ArrayEach(stack, func(frame []byte,
+		_ jsonparser.ValueType, _ int, _ error) {
+		fn, _, _, _ := jsonparser.Get(frame, "func")
+		file, _, _, _ := jsonparser.Get(frame, "file")
+		line, _ := jsonparser.GetInt(frame, "line")
+
+		buf.WriteString("\n\t")
+		writeUnescaped(buf, fn, sc.scratch)
+		buf.WriteString("\n\t\t")
+		writeUnescaped(buf, file, sc.scratch)
+		buf.WriteByte(':')
+		sc.scratch = strconv.AppendInt(sc.scratch[:0], line, 10)
+		buf.Write(sc.scratch)
+	})
+}
+
+type errorConverter struct {
+	next    Converter
+	causes  bool
+	scratch []byte
+}
+
+func newErrorConverter() Converter {
+	return &errorConverter{
+		scratch: make([]byte, 0, 128),
+	}
+}
+
+func (ec *errorConverter) AttachNext(next Converter) {
+	ec.next = next
+}
+
+func (ec *errorConverter) Next() Converter {
+	return ec.next
+}
+
+func (ec *errorConverter) AttachChild(_ Converter) {
+}
+
+func (ec *errorConverter) AttachOptions(opts []string) {
+	ec.causes = len(opts) > 0 && opts[0] == "causes"
+}
+
+// Convert writes the message of error. If the option is causes, each cause will be
+// written in new line with its type, and indented with tabs according to the depth.
+func (ec *errorConverter) Convert(origin interface{}, buf *bytes.Buffer) {
+	e, ok := origin.(*LogEvent)
+	if !ok {
+		return
+	}
+
+	var causes []byte
+	_ = e.Fields(func(k, v []byte, _ bool) error {
+		switch string(k) {
+		case ErrorFieldKey:
+			writeUnescaped(buf, v, ec.scratch)
+		case ErrorCausesFieldKey:
+			causes = v
+		}
+		return nil
+	})
+
+	if ec.causes && len(causes) > 0 {
+		ec.writeCauses(causes, 1, buf)
+	}
+}
+
+func (ec *errorConverter) writeCauses(causes []byte, depth int, buf *bytes.Buffer) {
+	_, _ = jsonparser.ArrayEach(causes, func(cause []byte,
+		_ jsonparser.ValueType, _ int, _ error) {
+		typ, _, _, _ := jsonparser.Get(cause, errorTypeKey)
+		msg, _, _, _ := jsonparser.Get(cause, errorMessageKey)
+
+		buf.WriteByte('\n')
+		for i := 0; i < depth; i++ {
+			buf.WriteByte('\t')
+		}
+		buf.WriteString("caused by ")
+		writeUnescaped(buf, typ, ec.scratch)
+		buf.WriteString(": ")
+		writeUnescaped(buf, msg, ec.scratch)
+
+		if next, _, _, err := jsonparser.Get(cause, errorCausesKey); err == nil {
+			ec.writeCauses(next, depth+1, buf)
+		}
+	})
+}
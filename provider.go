@@ -32,9 +32,13 @@ func (p *BaseProvider) Name() string {
 }
 
 func (p *BaseProvider) Prepare() {
-	p.configurators = append(p.configurators, manual)
+	configurators := append([]Configurator{levels}, p.configurators...)
+	configurators = append(configurators, addedConfigurators()...)
+	configurators = append(configurators, manual)
 
-	for _, c := range p.configurators {
+	// manual hooks and loggers are installed no matter which configurator stops the chain
+	manual.attach(p.context)
+	for _, c := range configurators {
 		if c.Configure(p.context) == StatusNoNext {
 			return
 		}
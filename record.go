@@ -15,6 +15,7 @@
 package lork
 
 import (
+	"context"
 	"time"
 )
 
@@ -29,10 +30,12 @@ type Record interface {
 	// Bytes adds byte array value to this record.
 	Bytes(key string, val []byte) Record
 
-	// Err adds err to this record.
+	// Err adds err to this record. The message, concrete type, details of ErrorMarshaler
+	// and the causes unwrapped from err will be added.
 	Err(err error) Record
 
-	// Errs adds err array to this record.
+	// Errs adds err array to this record, each error will be encoded as an object
+	// with message, type, details and causes.
 	Errs(key string, errs []error) Record
 
 	// Bool adds bool value to this record.
@@ -128,6 +131,26 @@ type Record interface {
 	// Any adds any value to this record.
 	Any(key string, val interface{}) Record
 
+	// Dict adds a nested object with the fields added in given function.
+	Dict(key string, dict func(r Record)) Record
+
+	// Object adds a nested object with ObjectMarshaler.
+	Object(key string, obj ObjectMarshaler) Record
+
+	// Array adds an array with ArrayMarshaler.
+	Array(key string, arr ArrayMarshaler) Record
+
+	// Ctx adds the values in context as fields with registered ContextExtractor.
+	Ctx(ctx context.Context) Record
+
+	// Caller adds the file, line and function of the caller when this record is
+	// written. The argument skip is the number of extra stack frames to ascend,
+	// with 0 identifying the caller of Msg.
+	Caller(skip int) Record
+
+	// Stack adds the stack trace of current goroutine when this record is written.
+	Stack() Record
+
 	// Msge outputs log without message
 	Msge()
 
@@ -137,3 +160,79 @@ type Record interface {
 	// Msgf adds a message with format to this record and output log.
 	Msgf(format string, v ...interface{})
 }
+
+// ObjectMarshaler represents an object which can add its fields into record.
+type ObjectMarshaler interface {
+	// MarshalLogObject adds the fields of object into record.
+	MarshalLogObject(r Record)
+}
+
+// ObjectMarshalerFunc is a function to implement ObjectMarshaler.
+type ObjectMarshalerFunc func(r Record)
+
+// MarshalLogObject calls the function itself.
+func (f ObjectMarshalerFunc) MarshalLogObject(r Record) {
+	f(r)
+}
+
+// ErrorMarshaler represents an error which can add its own structured fields.
+type ErrorMarshaler interface {
+	// MarshalLogError adds the fields of error into record.
+	MarshalLogError(r Record)
+}
+
+// ArrayMarshaler represents an array which can add its elements with ArrayEncoder.
+type ArrayMarshaler interface {
+	// MarshalLogArray adds the elements of array with encoder.
+	MarshalLogArray(a ArrayEncoder)
+}
+
+// ArrayMarshalerFunc is a function to implement ArrayMarshaler.
+type ArrayMarshalerFunc func(a ArrayEncoder)
+
+// MarshalLogArray calls the function itself.
+func (f ArrayMarshalerFunc) MarshalLogArray(a ArrayEncoder) {
+	f(a)
+}
+
+// ArrayEncoder represents an encoder to add elements into array.
+type ArrayEncoder interface {
+	// Str adds string element to array.
+	Str(val string) ArrayEncoder
+
+	// Bool adds bool element to array.
+	Bool(val bool) ArrayEncoder
+
+	// Int adds int element to array.
+	Int(val int) ArrayEncoder
+
+	// Int64 adds int64 element to array.
+	Int64(val int64) ArrayEncoder
+
+	// Uint64 adds uint64 element to array.
+	Uint64(val uint64) ArrayEncoder
+
+	// Float64 adds float64 element to array.
+	Float64(val float64) ArrayEncoder
+
+	// Time adds time element to array.
+	Time(val time.Time) ArrayEncoder
+
+	// Dur adds duration element to array.
+	Dur(val time.Duration) ArrayEncoder
+
+	// Err adds error element to array, the error will be encoded as an object.
+	Err(err error) ArrayEncoder
+
+	// Any adds any element to array.
+	Any(val interface{}) ArrayEncoder
+
+	// Dict adds object element with the fields added in given function.
+	Dict(dict func(r Record)) ArrayEncoder
+
+	// Object adds object element with ObjectMarshaler.
+	Object(obj ObjectMarshaler) ArrayEncoder
+
+	// Array adds nested array element with ArrayMarshaler.
+	Array(arr ArrayMarshaler) ArrayEncoder
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ComponentConfig represents the raw config of a component in declarative config,
+// such as encoder or filter, and the type of component is in the "type" key.
+type ComponentConfig map[string]interface{}
+
+// OptionDecoder decodes the options of component in declarative config, and builds
+// the nested components with registered factories.
+type OptionDecoder interface {
+	// Name returns the name of the component, it's only available for writers.
+	Name() string
+
+	// Decode decodes the options into v with json tags, unknown options are reported
+	// as error.
+	Decode(v interface{}) error
+
+	// Encoder builds encoder with given config, nil will be returned if config is nil.
+	Encoder(c ComponentConfig) (Encoder, error)
+
+	// Filter builds filter with given config, nil will be returned if config is nil.
+	Filter(c ComponentConfig) (Filter, error)
+
+	// RollingPolicy builds rolling policy with given config, nil will be returned if
+	// config is nil.
+	RollingPolicy(c ComponentConfig) (RollingPolicy, error)
+
+	// Archiver builds archiver with given config, nil will be returned if config is nil.
+	Archiver(c ComponentConfig) (Archiver, error)
+
+	// Writers builds the writers referenced by name.
+	Writers(names []string) ([]Writer, error)
+
+	// OnApply registers a function which will be called when the config is applied,
+	// before the writers are started. The writers referenced should be started and
+	// attached here, the config will not be applied if an error is returned.
+	OnApply(f func() error)
+}
+
+// WriterFactory creates a writer with options decoded by OptionDecoder.
+type WriterFactory func(d OptionDecoder) (Writer, error)
+
+// EncoderFactory creates an encoder with options decoded by OptionDecoder.
+type EncoderFactory func(d OptionDecoder) (Encoder, error)
+
+// FilterFactory creates a filter with options decoded by OptionDecoder.
+type FilterFactory func(d OptionDecoder) (Filter, error)
+
+// RollingPolicyFactory creates a rolling policy with options decoded by OptionDecoder.
+type RollingPolicyFactory func(d OptionDecoder) (RollingPolicy, error)
+
+// ArchiverFactory creates an archiver with options decoded by OptionDecoder.
+type ArchiverFactory func(d OptionDecoder) (Archiver, error)
+
+type componentKind string
+
+const (
+	writerKind        componentKind = "writer"
+	encoderKind       componentKind = "encoder"
+	filterKind        componentKind = "filter"
+	rollingPolicyKind componentKind = "rolling policy"
+	archiverKind      componentKind = "archiver"
+)
+
+// registry holds the factories of components by kind and type name.
+type registry struct {
+	locker    sync.RWMutex
+	factories map[componentKind]map[string]interface{}
+}
+
+var factories = &registry{
+	factories: make(map[componentKind]map[string]interface{}),
+}
+
+// RegisterWriter registers the writer factory with given type name. It panics if
+// the type name is registered twice.
+func RegisterWriter(typ string, factory WriterFactory) {
+	factories.register(writerKind, typ, factory)
+}
+
+// RegisterEncoder registers the encoder factory with given type name. It panics if
+// the type name is registered twice.
+func RegisterEncoder(typ string, factory EncoderFactory) {
+	factories.register(encoderKind, typ, factory)
+}
+
+// RegisterFilter registers the filter factory with given type name. It panics if
+// the type name is registered twice.
+func RegisterFilter(typ string, factory FilterFactory) {
+	factories.register(filterKind, typ, factory)
+}
+
+// RegisterRollingPolicy registers the rolling policy factory with given type name.
+// It panics if the type name is registered twice.
+func RegisterRollingPolicy(typ string, factory RollingPolicyFactory) {
+	factories.register(rollingPolicyKind, typ, factory)
+}
+
+// RegisterArchiver registers the archiver factory with given type name. It panics if
+// the type name is registered twice.
+func RegisterArchiver(typ string, factory ArchiverFactory) {
+	factories.register(archiverKind, typ, factory)
+}
+
+func (r *registry) register(kind componentKind, typ string, factory interface{}) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	typ = strings.ToLower(typ)
+	if len(typ) == 0 {
+		panic(fmt.Sprintf("lork: register %s with empty type", kind))
+	}
+	if _, ok := r.factories[kind][typ]; ok {
+		panic(fmt.Sprintf("lork: register %s type %q twice", kind, typ))
+	}
+	if r.factories[kind] == nil {
+		r.factories[kind] = make(map[string]interface{})
+	}
+	r.factories[kind][typ] = factory
+}
+
+// lookup finds the factory of given kind and type name.
+func (r *registry) lookup(kind componentKind, typ string) (interface{}, error) {
+	r.locker.RLock()
+	defer r.locker.RUnlock()
+
+	factory, ok := r.factories[kind][strings.ToLower(typ)]
+	if !ok {
+		return nil, fmt.Errorf("unknown %s type %q", kind, typ)
+	}
+
+	return factory, nil
+}
+
+// componentDecoder is the OptionDecoder of a component in config file.
+type componentDecoder struct {
+	builder *configBuilder
+	path    string
+	name    string
+	config  ComponentConfig
+}
+
+func (d *componentDecoder) Name() string {
+	return d.name
+}
+
+func (d *componentDecoder) Decode(v interface{}) error {
+	options := make(map[string]interface{}, len(d.config))
+	for k, val := range d.config {
+		if k != "type" && k != "name" {
+			options[k] = val
+		}
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(v); err != nil {
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	return nil
+}
+
+func (d *componentDecoder) Encoder(c ComponentConfig) (Encoder, error) {
+	if c == nil {
+		return nil, nil
+	}
+	factory, err := d.lookup(encoderKind, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.(EncoderFactory)(d.nested(c))
+}
+
+func (d *componentDecoder) Filter(c ComponentConfig) (Filter, error) {
+	if c == nil {
+		return nil, nil
+	}
+	factory, err := d.lookup(filterKind, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.(FilterFactory)(d.nested(c))
+}
+
+func (d *componentDecoder) RollingPolicy(c ComponentConfig) (RollingPolicy, error) {
+	if c == nil {
+		return nil, nil
+	}
+	factory, err := d.lookup(rollingPolicyKind, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.(RollingPolicyFactory)(d.nested(c))
+}
+
+func (d *componentDecoder) Archiver(c ComponentConfig) (Archiver, error) {
+	if c == nil {
+		return nil, nil
+	}
+	factory, err := d.lookup(archiverKind, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.(ArchiverFactory)(d.nested(c))
+}
+
+func (d *componentDecoder) Writers(names []string) ([]Writer, error) {
+	return d.builder.refWriters(d.path, names)
+}
+
+func (d *componentDecoder) OnApply(f func() error) {
+	d.builder.applies = append(d.builder.applies, configApply{writer: d.name, apply: f})
+}
+
+// lookup finds the factory with the type in config.
+func (d *componentDecoder) lookup(kind componentKind, c ComponentConfig) (interface{}, error) {
+	typ, _ := c["type"].(string)
+	if len(typ) == 0 {
+		return nil, errors.New("missing type")
+	}
+
+	return factories.lookup(kind, typ)
+}
+
+// nested creates a decoder for the nested component.
+func (d *componentDecoder) nested(c ComponentConfig) *componentDecoder {
+	return &componentDecoder{
+		builder: d.builder,
+		path:    d.path,
+		config:  c,
+	}
+}
+
+// parseDuration parses duration option, 0 will be returned if it's empty.
+func parseDuration(key, value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type registryTestWriter struct {
+	name   string
+	prefix string
+	filter Filter
+}
+
+func (w *registryTestWriter) Name() string {
+	return w.name
+}
+
+func (w *registryTestWriter) DoWrite(_ *LogEvent) error {
+	return nil
+}
+
+var _ = ginkgo.Describe("registry", func() {
+	ginkgo.BeforeEach(func() {
+		factories.locker.Lock()
+		delete(factories.factories[writerKind], "registry_test")
+		factories.locker.Unlock()
+	})
+
+	ginkgo.It("register and build", func() {
+		RegisterWriter("registry_test", func(d OptionDecoder) (Writer, error) {
+			var c struct {
+				Prefix string          `json:"prefix"`
+				Filter ComponentConfig `json:"filter"`
+			}
+			if err := d.Decode(&c); err != nil {
+				return nil, err
+			}
+			if len(c.Prefix) == 0 {
+				return nil, errors.New("missing prefix")
+			}
+			filter, err := d.Filter(c.Filter)
+			if err != nil {
+				return nil, err
+			}
+			return &registryTestWriter{name: d.Name(), prefix: c.Prefix, filter: filter}, nil
+		})
+		Expect(func() {
+			RegisterWriter("REGISTRY_TEST", nil)
+		}).To(Panic())
+
+		dir, err := os.MkdirTemp("", "lork-registry")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		filename := filepath.Join(dir, "lork.json")
+		Expect(os.WriteFile(filename, []byte(`{
+  "writers": [{"name": "CUSTOM", "type": "registry_test", "prefix": ">",
+    "filter": {"type": "threshold", "level": "warn"}}],
+  "loggers": [{"name": "ROOT", "writers": ["CUSTOM"]}]
+}`), 0644)).To(BeNil())
+		fc, err := NewFileConfigurator(filename)
+		Expect(err).To(BeNil())
+		w := fc.config.loggers[0].writers[0].(*registryTestWriter)
+		Expect(w.name).To(Equal("CUSTOM"))
+		Expect(w.prefix).To(Equal(">"))
+		Expect(w.filter).To(Equal(NewThresholdFilter(WarnLevel)))
+
+		Expect(os.WriteFile(filename, []byte(`{
+  "writers": [{"name": "CUSTOM", "type": "registry_test", "suffix": "<"}],
+  "loggers": [{"name": "ROOT", "writers": ["CUSTOM"]}]
+}`), 0644)).To(BeNil())
+		_, err = NewFileConfigurator(filename)
+		Expect(err).To(MatchError(ContainSubstring(`writers[0] (CUSTOM): unknown field "suffix"`)))
+	})
+	ginkgo.It("builtin types", func() {
+		for _, typ := range []string{"console", "file", "async", "socket"} {
+			_, err := factories.lookup(writerKind, typ)
+			Expect(err).To(BeNil())
+		}
+		for _, typ := range []string{"gzip", "zip", "none"} {
+			_, err := factories.lookup(archiverKind, typ)
+			Expect(err).To(BeNil())
+		}
+		_, err := factories.lookup(encoderKind, "xml")
+		Expect(err).To(MatchError(`unknown encoder type "xml"`))
+	})
+})
@@ -16,10 +16,12 @@ package lork
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -39,6 +41,7 @@ type timeBasedArchiveRemover struct {
 	parentClean bool
 
 	lastClean int64
+	wg        sync.WaitGroup
 }
 
 func newTimeBasedArchiveRemover(fp *filenamePattern, rd *rollingDate) ArchiveRemover {
@@ -62,7 +65,9 @@ func (r *timeBasedArchiveRemover) CleanAsync(now time.Time) {
 
 func (r *timeBasedArchiveRemover) cleanAsync(now time.Time,
 	listFilesInPeriod func(t time.Time) []string) {
+	r.wg.Add(1)
 	go func() {
+		defer r.wg.Done()
 		periodsElapsed := r.calcElapsedPeriods(now)
 		r.lastClean = time.Now().Unix()
 		for i := 0; i < periodsElapsed; i++ {
@@ -87,6 +92,11 @@ func (r *timeBasedArchiveRemover) cleanAsync(now time.Time,
 	}()
 }
 
+// Flush waits until all the in-flight cleaning finished.
+func (r *timeBasedArchiveRemover) Flush(ctx context.Context) error {
+	return waitGroupDone(ctx, &r.wg, "cleaning archives in progress")
+}
+
 func (r *timeBasedArchiveRemover) calcElapsedPeriods(now time.Time) int {
 	var periodsElapsed = 0
 	nowUnix := now.Unix()
@@ -15,6 +15,7 @@
 package lork
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -47,6 +48,17 @@ func NewNoopRollingPolicy() RollingPolicy {
 	return &noopRollingPolicy{}
 }
 
+func init() {
+	RegisterRollingPolicy("noop", func(d OptionDecoder) (RollingPolicy, error) {
+		if err := d.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return NewNoopRollingPolicy(), nil
+	})
+	RegisterRollingPolicy("time_based", newTimeBasedRollingPolicyFromConfig)
+	RegisterRollingPolicy("size_and_time_based", newSizeAndTimeBasedRollingPolicyFromConfig)
+}
+
 func (rp *noopRollingPolicy) Prepare() error {
 	return nil
 }
@@ -80,6 +92,9 @@ type timeBasedRollingPolicy struct {
 type TimeBasedRPOption struct {
 	FilenamePattern string
 	MaxHistory      int
+	// Archiver archives the rolled files, it's decided by the suffix of
+	// filename pattern if not set.
+	Archiver Archiver
 }
 
 // NewTimeBasedRollingPolicy creates an instance of time based rolling policy
@@ -99,11 +114,42 @@ func NewTimeBasedRollingPolicy(options ...func(*TimeBasedRPOption)) RollingPolic
 		return nil
 	}
 
+	archiver := opts.Archiver
+	if archiver == nil {
+		archiver = newArchiver(opts.FilenamePattern)
+	}
+
 	return &timeBasedRollingPolicy{
 		maxHistory:      opts.MaxHistory,
 		filenamePattern: fp,
-		archiver:        newArchiver(opts.FilenamePattern),
+		archiver:        archiver,
+	}
+}
+
+type timeBasedRPConfig struct {
+	FilenamePattern string          `json:"filename_pattern"`
+	MaxHistory      int             `json:"max_history"`
+	Archiver        ComponentConfig `json:"archiver"`
+}
+
+func newTimeBasedRollingPolicyFromConfig(d OptionDecoder) (RollingPolicy, error) {
+	var c timeBasedRPConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if err := checkFilenamePattern(c.FilenamePattern, false); err != nil {
+		return nil, err
+	}
+	archiver, err := d.Archiver(c.Archiver)
+	if err != nil {
+		return nil, fmt.Errorf("archiver: %w", err)
 	}
+
+	return NewTimeBasedRollingPolicy(func(o *TimeBasedRPOption) {
+		o.FilenamePattern = c.FilenamePattern
+		o.MaxHistory = c.MaxHistory
+		o.Archiver = archiver
+	}), nil
 }
 
 func (rp *timeBasedRollingPolicy) Prepare() error {
@@ -145,6 +191,15 @@ func (rp *timeBasedRollingPolicy) Rotate() error {
 	return err
 }
 
+// Flush waits until the in-flight archiving and cleaning finished.
+func (rp *timeBasedRollingPolicy) Flush(ctx context.Context) error {
+	if err := flush(ctx, rp.archiver); err != nil {
+		return err
+	}
+
+	return flush(ctx, rp.archiveRemover)
+}
+
 func (rp *timeBasedRollingPolicy) prepare(
 	newArchiveRemover func(*filenamePattern, *rollingDate) ArchiveRemover) error {
 	datePattern := rp.filenamePattern.datePattern()
@@ -190,6 +245,9 @@ type SizeAndTimeBasedRPOption struct {
 	FilenamePattern string
 	MaxFileSize     string
 	MaxHistory      int
+	// Archiver archives the rolled files, it's decided by the suffix of
+	// filename pattern if not set.
+	Archiver Archiver
 }
 
 // NewSizeAndTimeBasedRollingPolicy creates a new instance of size and time
@@ -213,6 +271,7 @@ func NewSizeAndTimeBasedRollingPolicy(options ...func(
 	tbrp := NewTimeBasedRollingPolicy(func(o *TimeBasedRPOption) {
 		o.FilenamePattern = opts.FilenamePattern
 		o.MaxHistory = opts.MaxHistory
+		o.Archiver = opts.Archiver
 	}).(*timeBasedRollingPolicy)
 	return &sizeAndTimeBasedRollingPolicy{
 		timeBasedRollingPolicy: tbrp,
@@ -220,6 +279,41 @@ func NewSizeAndTimeBasedRollingPolicy(options ...func(
 	}
 }
 
+type sizeAndTimeBasedRPConfig struct {
+	FilenamePattern string          `json:"filename_pattern"`
+	MaxFileSize     string          `json:"max_file_size"`
+	MaxHistory      int             `json:"max_history"`
+	Archiver        ComponentConfig `json:"archiver"`
+}
+
+func newSizeAndTimeBasedRollingPolicyFromConfig(d OptionDecoder) (RollingPolicy, error) {
+	var c sizeAndTimeBasedRPConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if err := checkFilenamePattern(c.FilenamePattern, true); err != nil {
+		return nil, err
+	}
+	if len(c.MaxFileSize) != 0 {
+		if _, err := parseFileSize(c.MaxFileSize); err != nil {
+			return nil, fmt.Errorf("invalid max_file_size %q", c.MaxFileSize)
+		}
+	}
+	archiver, err := d.Archiver(c.Archiver)
+	if err != nil {
+		return nil, fmt.Errorf("archiver: %w", err)
+	}
+
+	return NewSizeAndTimeBasedRollingPolicy(func(o *SizeAndTimeBasedRPOption) {
+		o.FilenamePattern = c.FilenamePattern
+		o.MaxHistory = c.MaxHistory
+		if len(c.MaxFileSize) != 0 {
+			o.MaxFileSize = c.MaxFileSize
+		}
+		o.Archiver = archiver
+	}), nil
+}
+
 func (rp *sizeAndTimeBasedRollingPolicy) Prepare() error {
 	if rp.fileWriter == nil {
 		return errors.New("rolling policy is not attached to a file writer")
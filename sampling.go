@@ -0,0 +1,406 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// samplingCounterSize is the number of counters for each level in burst sampler,
+	// messages with the same hash will share one counter.
+	samplingCounterSize = 1024
+	// samplingSummaryInterval is the default interval to emit summary of dropped events.
+	samplingSummaryInterval = time.Minute
+
+	samplingDroppedFieldKey = "dropped"
+)
+
+// Sampler decides which events should be logged.
+type Sampler interface {
+	// Sample returns true if the event should be logged.
+	Sample(e *LogEvent) bool
+}
+
+// SamplerFunc is a function to implement Sampler.
+type SamplerFunc func(e *LogEvent) bool
+
+// Sample calls the function itself.
+func (f SamplerFunc) Sample(e *LogEvent) bool {
+	return f(e)
+}
+
+// BurstSamplerOption represents available options for burst sampler.
+type BurstSamplerOption struct {
+	// First is the number of events logged in each interval with same level and message.
+	First uint64
+	// Thereafter means every Mth event will be logged after the first events,
+	// and all the rest will be dropped if it's zero.
+	Thereafter uint64
+	// Interval is the interval to reset the counters, default is one second.
+	Interval time.Duration
+}
+
+type samplingCounter struct {
+	resetAt int64
+	count   uint64
+}
+
+// incr increases the counter and returns current count, the counter will be reset
+// if the interval elapsed.
+func (c *samplingCounter) incr(now int64, interval int64) uint64 {
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > now {
+		return atomic.AddUint64(&c.count, 1)
+	}
+
+	atomic.StoreUint64(&c.count, 1)
+	atomic.StoreInt64(&c.resetAt, now+interval)
+
+	return 1
+}
+
+// burstSampler is a sampler which logs first N events then every Mth event in each
+// interval, keyed by level and message.
+type burstSampler struct {
+	first      uint64
+	thereafter uint64
+	interval   int64
+	counters   [OffLevel][samplingCounterSize]samplingCounter
+}
+
+// NewBurstSampler creates a sampler which logs the first N events and then every
+// Mth event in each interval, the events are counted by level and message.
+func NewBurstSampler(options ...func(*BurstSamplerOption)) Sampler {
+	opts := &BurstSamplerOption{
+		Interval: time.Second,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	return &burstSampler{
+		first:      opts.First,
+		thereafter: opts.Thereafter,
+		interval:   opts.Interval.Nanoseconds(),
+	}
+}
+
+func (s *burstSampler) Sample(e *LogEvent) bool {
+	lvl := e.LevelInt()
+	if lvl < TraceLevel || lvl >= OffLevel {
+		return true
+	}
+
+	c := &s.counters[lvl][fnv32a(e.Message())%samplingCounterSize]
+	n := c.incr(e.Timestamp(), s.interval)
+	if n <= s.first {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// randomSampler is a sampler which logs events with given ratio.
+type randomSampler struct {
+	ratio float64
+}
+
+// NewRandomSampler creates a sampler which logs events randomly with given ratio
+// between 0 and 1.
+func NewRandomSampler(ratio float64) Sampler {
+	return &randomSampler{
+		ratio: ratio,
+	}
+}
+
+func (s *randomSampler) Sample(_ *LogEvent) bool {
+	return rand.Float64() < s.ratio
+}
+
+// droppedCounter counts the events dropped by sampler and makes summary event
+// periodically.
+type droppedCounter struct {
+	interval    int64
+	dropped     uint64
+	lastSummary int64
+}
+
+func newDroppedCounter(interval time.Duration) *droppedCounter {
+	return &droppedCounter{
+		interval:    interval.Nanoseconds(),
+		lastSummary: time.Now().UnixNano(),
+	}
+}
+
+func (c *droppedCounter) drop() {
+	atomic.AddUint64(&c.dropped, 1)
+}
+
+// summary makes a summary event if the interval elapsed or force is true. It returns
+// nil if there is nothing dropped.
+func (c *droppedCounter) summary(name string, force bool) *LogEvent {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&c.lastSummary)
+	if !force && now-last < c.interval {
+		return nil
+	}
+	if !atomic.CompareAndSwapInt64(&c.lastSummary, last, now) {
+		return nil
+	}
+
+	dropped := atomic.SwapUint64(&c.dropped, 0)
+	if dropped == 0 {
+		return nil
+	}
+
+	e := NewLogEvent()
+	e.appendLevel(WarnLevel)
+	e.loggerName.WriteString(name)
+	e.appendMessage("events dropped by sampling")
+	e.appendUint(samplingDroppedFieldKey, dropped)
+
+	return e
+}
+
+// samplingHook is a hook which samples the events of named logger. The summary
+// event will be written into writers directly by a ticker once started.
+type samplingHook struct {
+	name     string
+	sampler  Sampler
+	counter  *droppedCounter
+	writer   *MultiWriter
+	interval time.Duration
+
+	locker sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newSamplingHook(name string, sampler Sampler, interval time.Duration,
+	writer *MultiWriter) *samplingHook {
+	return &samplingHook{
+		name:     name,
+		sampler:  sampler,
+		counter:  newDroppedCounter(interval),
+		writer:   writer,
+		interval: interval,
+	}
+}
+
+// Start starts the ticker to write summary of dropped events periodically.
+func (h *samplingHook) Start() {
+	h.locker.Lock()
+	defer h.locker.Unlock()
+
+	if h.stop != nil {
+		return
+	}
+	h.stop = make(chan struct{})
+	h.done = make(chan struct{})
+	go h.run(h.stop, h.done)
+}
+
+// Stop stops the ticker and writes the summary of events dropped since last summary.
+func (h *samplingHook) Stop() {
+	h.locker.Lock()
+	stop, done := h.stop, h.done
+	h.stop, h.done = nil, nil
+	h.locker.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	h.writeSummary(true)
+}
+
+func (h *samplingHook) Fire(e *LogEvent) bool {
+	if h.sampler.Sample(e) {
+		return true
+	}
+	h.counter.drop()
+
+	return false
+}
+
+func (h *samplingHook) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			h.writeSummary(false)
+		}
+	}
+}
+
+func (h *samplingHook) writeSummary(force bool) {
+	if summary := h.counter.summary(h.name, force); summary != nil {
+		h.writer.writeToAll(summary)
+		summary.Recycle()
+	}
+}
+
+// SamplingWriterOption represents available options for sampling writer.
+type SamplingWriterOption struct {
+	// Writer is the writer to write sampled events.
+	Writer Writer
+	// Sampler decides which events will be written.
+	Sampler Sampler
+	// SummaryInterval is the interval to write summary of dropped events, default
+	// is one minute.
+	SummaryInterval time.Duration
+}
+
+// samplingWriter is a writer which samples the events. The summary event will be
+// written into the wrapped writer by a ticker once started.
+type samplingWriter struct {
+	opts    *SamplingWriterOption
+	counter *droppedCounter
+
+	locker sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewSamplingWriter creates a writer which only writes the events sampled by sampler
+// into given writer, and writes a summary of dropped events periodically.
+func NewSamplingWriter(options ...func(*SamplingWriterOption)) Writer {
+	opts := &SamplingWriterOption{
+		SummaryInterval: samplingSummaryInterval,
+	}
+	for _, f := range options {
+		f(opts)
+	}
+
+	if opts.Writer == nil {
+		ReportfExit("sampling writer needs a writer")
+	}
+	if opts.Sampler == nil {
+		ReportfExit("sampling writer needs a sampler")
+	}
+
+	return &samplingWriter{
+		opts:    opts,
+		counter: newDroppedCounter(opts.SummaryInterval),
+	}
+}
+
+// Start starts the wrapped writer, and the ticker to write summary of dropped events
+// periodically.
+func (w *samplingWriter) Start() {
+	if lc, ok := w.opts.Writer.(Lifecycle); ok {
+		lc.Start()
+	}
+	w.startTicker()
+}
+
+func (w *samplingWriter) StartChecked() error {
+	if err := startChecked(w.opts.Writer); err != nil {
+		return err
+	}
+	w.startTicker()
+
+	return nil
+}
+
+func (w *samplingWriter) startTicker() {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	if w.stop != nil || w.opts.SummaryInterval <= 0 {
+		return
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.run(w.stop, w.done)
+}
+
+func (w *samplingWriter) Stop() {
+	w.StopContext(context.Background())
+}
+
+// StopContext stops the ticker and writes the summary of events dropped since last
+// summary, then stops the wrapped writer.
+func (w *samplingWriter) StopContext(ctx context.Context) {
+	w.locker.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.locker.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	w.writeSummary(true)
+	stopContext(ctx, w.opts.Writer)
+}
+
+func (w *samplingWriter) Flush(ctx context.Context) error {
+	return flush(ctx, w.opts.Writer)
+}
+
+func (w *samplingWriter) Health() error {
+	return checkHealth(w.opts.Writer)
+}
+
+func (w *samplingWriter) Name() string {
+	return w.opts.Writer.Name()
+}
+
+func (w *samplingWriter) DoWrite(event *LogEvent) error {
+	if !w.opts.Sampler.Sample(event) {
+		w.counter.drop()
+		return nil
+	}
+
+	return w.opts.Writer.DoWrite(event)
+}
+
+func (w *samplingWriter) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.opts.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.writeSummary(false)
+		}
+	}
+}
+
+func (w *samplingWriter) writeSummary(force bool) {
+	summary := w.counter.summary(RootLoggerName, force)
+	if summary == nil {
+		return
+	}
+
+	if err := w.opts.Writer.DoWrite(summary); err != nil {
+		Reportf("write sampling summary error: %v", err)
+	}
+	summary.Recycle()
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("sampling", func() {
+	ginkgo.It("burst sampler", func() {
+		sampler := NewBurstSampler(func(o *BurstSamplerOption) {
+			o.First = 2
+			o.Thereafter = 3
+		})
+		event := MakeEvent([]byte(`{"time":"2023-01-01T00:00:00Z","level":"INFO","message":"m"}`))
+		var results []bool
+		for i := 0; i < 8; i++ {
+			results = append(results, sampler.Sample(event))
+		}
+		Expect(results).To(Equal([]bool{true, true, false, false, true, false, false, true}))
+
+		other := MakeEvent([]byte(`{"time":"2023-01-01T00:00:00Z","level":"WARN","message":"m"}`))
+		Expect(sampler.Sample(other)).To(BeTrue())
+
+		later := MakeEvent([]byte(`{"time":"2023-01-01T00:00:02Z","level":"INFO","message":"m"}`))
+		Expect(sampler.Sample(later)).To(BeTrue())
+
+		allocs := testing.AllocsPerRun(100, func() {
+			sampler.Sample(event)
+		})
+		Expect(allocs).To(BeZero())
+	})
+	ginkgo.It("random sampler", func() {
+		event := MakeEvent([]byte(`{"level":"INFO","message":"m"}`))
+		Expect(NewRandomSampler(0).Sample(event)).To(BeFalse())
+		Expect(NewRandomSampler(1).Sample(event)).To(BeTrue())
+	})
+	ginkgo.It("sampling writer", func() {
+		collector := &eventCollector{}
+		writer := NewSamplingWriter(func(o *SamplingWriterOption) {
+			o.Writer = collector
+			o.Sampler = SamplerFunc(func(e *LogEvent) bool {
+				return string(e.Message()) == "keep"
+			})
+			o.SummaryInterval = time.Nanosecond
+		})
+		write := func(msg string) {
+			event := MakeEvent([]byte(`{"level":"INFO","message":"` + msg + `"}`))
+			Expect(writer.DoWrite(event)).To(BeNil())
+			event.Recycle()
+		}
+
+		write("drop")
+		Expect(collector.events).To(BeEmpty())
+		write("keep")
+		Expect(collector.events).To(HaveLen(1))
+		Expect(string(collector.events[0].Message())).To(Equal("keep"))
+
+		write("drop")
+		writer.(Lifecycle).Stop()
+		Expect(collector.events).To(HaveLen(2))
+		Expect(collector.last().LevelInt()).To(Equal(WarnLevel))
+		Expect(collectFields(collector.last())).To(Equal("dropped=2 "))
+	})
+	ginkgo.It("write summary of sampling writer periodically", func() {
+		collector := &lockedCollector{}
+		writer := NewSamplingWriter(func(o *SamplingWriterOption) {
+			o.Writer = collector
+			o.Sampler = SamplerFunc(func(e *LogEvent) bool {
+				return false
+			})
+			o.SummaryInterval = 10 * time.Millisecond
+		})
+		writer.(Lifecycle).Start()
+
+		event := MakeEvent([]byte(`{"level":"INFO","message":"noisy"}`))
+		Expect(writer.DoWrite(event)).To(BeNil())
+		event.Recycle()
+		Eventually(collector.fields).Should(Equal([]string{"dropped=1 "}))
+
+		writer.(Lifecycle).Stop()
+		Expect(collector.fields()).To(Equal([]string{"dropped=1 "}))
+	})
+	ginkgo.It("sampler of named logger", func() {
+		ctx, collector := newCollectorContext()
+		sampler := SamplerFunc(func(e *LogEvent) bool {
+			return string(e.Message()) != "noisy"
+		})
+		ctx.RealLogger("a").SetSampler(sampler)
+
+		ctx.Logger("a/b").Info().Msg("noisy")
+		ctx.Logger("b").Info().Msg("noisy")
+		Expect(collector.events).To(HaveLen(1))
+		Expect(string(collector.last().LoggerName())).To(Equal("b"))
+
+		// summary is written once sampler is removed
+		ctx.RealLogger("a").SetSampler(nil)
+		Expect(collector.events).To(HaveLen(2))
+		Expect(string(collector.last().LoggerName())).To(Equal("a"))
+		Expect(collectFields(collector.last())).To(Equal("dropped=1 "))
+		ctx.Logger("a").Info().Msg("noisy")
+		Expect(collector.events).To(HaveLen(3))
+
+		ctx.RealLogger("a").SetSampler(sampler)
+		ctx.Logger("a").Info().Msg("noisy")
+		ctx.Logger("a").Info().Msg("quiet")
+		Expect(collector.events).To(HaveLen(4))
+		Expect(string(collector.last().Message())).To(Equal("quiet"))
+		ctx.RealLogger("a").SetSampler(nil)
+		Expect(collector.events).To(HaveLen(5))
+		Expect(collectFields(collector.last())).To(Equal("dropped=1 "))
+	})
+	ginkgo.It("write summary of named logger periodically", func() {
+		ctx := NewLoggerContext(NewClassicLogger)
+		collector := &lockedCollector{}
+		ctx.RealLogger(RootLoggerName).AddWriter(collector)
+		mw := ctx.rootLogger.multiWriter
+		mw.hooks.setSampler("a", newSamplingHook("a", SamplerFunc(func(e *LogEvent) bool {
+			return false
+		}), 10*time.Millisecond, mw))
+
+		ctx.Logger("a").Info().Msg("noisy")
+		Eventually(collector.fields).Should(Equal([]string{"dropped=1 "}))
+
+		ctx.Logger("a").Info().Msg("noisy")
+		Expect(ctx.Shutdown(context.Background())).To(BeNil())
+		Expect(collector.fields()).To(Equal([]string{"dropped=1 ", "dropped=1 "}))
+	})
+})
+
+type lockedCollector struct {
+	locker sync.Mutex
+	events []string
+}
+
+func (w *lockedCollector) Name() string {
+	return "LOCKED_COLLECTOR"
+}
+
+func (w *lockedCollector) DoWrite(event *LogEvent) error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	w.events = append(w.events, collectFields(event))
+	return nil
+}
+
+func (w *lockedCollector) fields() []string {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	return append([]string(nil), w.events...)
+}
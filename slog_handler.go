@@ -0,0 +1,382 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package lork
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strconv"
+)
+
+// SlogHandlerOption represents available options for slog handler.
+type SlogHandlerOption struct {
+	// Name is the name of lork logger to write records.
+	Name string
+	// Logger is the lork logger to write records, the logger with Name will be
+	// used if not set.
+	Logger ILogger
+	// AddSource adds the caller of records if true.
+	AddSource bool
+	// Level is the minimum level of records, the level of lork logger will be
+	// used if not set.
+	Level slog.Leveler
+}
+
+// slogHandler is an implementation of slog.Handler which writes records into lork.
+type slogHandler struct {
+	name      string
+	logger    ILogger
+	addSource bool
+	level     slog.Leveler
+
+	// fields are the encoded attributes out of any group.
+	fields *LogEvent
+	// groups are the opened groups, and members are the encoded attributes
+	// in each group without braces.
+	groups  []string
+	members [][]byte
+}
+
+// NewSlogHandler creates a new instance of slog.Handler which will write records
+// with lork logger, the groups will be written as nested objects.
+func NewSlogHandler(options ...func(*SlogHandlerOption)) slog.Handler {
+	opts := &SlogHandlerOption{}
+	for _, f := range options {
+		f(opts)
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = Logger(opts.Name)
+	}
+
+	return &slogHandler{
+		name:      logger.Name(),
+		logger:    logger,
+		addSource: opts.AddSource,
+		level:     opts.Level,
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	if h.level != nil && lvl < h.level.Level() {
+		return false
+	}
+
+	return isLevelEnabled(h.logger, slogLvlToLorkLvl(lvl))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	event := NewLogEvent()
+	event.appendLevel(slogLvlToLorkLvl(record.Level))
+	event.loggerName.WriteString(h.name)
+	event.message.WriteString(record.Message)
+	if !record.Time.IsZero() {
+		event.unixNano = record.Time.UnixNano()
+	}
+	if h.addSource && record.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{record.PC})
+		frame, _ := frames.Next()
+		event.caller.WriteString(frame.File)
+		event.caller.WriteByte(':')
+		event.caller.WriteString(strconv.Itoa(frame.Line))
+		event.callerFunc.WriteString(frame.Function)
+	}
+
+	if h.fields != nil {
+		event.appendFields(h.fields)
+	}
+	h.extractContext(ctx, event)
+
+	if len(h.groups) == 0 {
+		record.Attrs(func(attr slog.Attr) bool {
+			appendSlogAttr(event, attr)
+			return true
+		})
+	} else {
+		h.appendGroups(event, record)
+	}
+
+	h.logger.Event(event)
+
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	child := h.clone()
+	if len(h.groups) == 0 {
+		fields := NewLogEvent()
+		if h.fields != nil {
+			fields.appendFields(h.fields)
+		}
+		for _, attr := range attrs {
+			appendSlogAttr(fields, attr)
+		}
+		child.fields = fields
+	} else {
+		last := len(child.members) - 1
+		members := append([]byte(nil), child.members[last]...)
+		for _, attr := range attrs {
+			members = appendSlogMember(members, attr)
+		}
+		child.members[last] = members
+	}
+
+	return child
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+
+	child := h.clone()
+	child.groups = append(child.groups, name)
+	child.members = append(child.members, nil)
+
+	return child
+}
+
+func (h *slogHandler) clone() *slogHandler {
+	return &slogHandler{
+		name:      h.name,
+		logger:    h.logger,
+		addSource: h.addSource,
+		level:     h.level,
+		fields:    h.fields,
+		groups:    h.groups[:len(h.groups):len(h.groups)],
+		members:   append([][]byte(nil), h.members...),
+	}
+}
+
+func (h *slogHandler) extractContext(ctx context.Context, event *LogEvent) {
+	var recorder EventRecorder
+	switch l := h.logger.(type) {
+	case *namedLogger:
+		recorder = l.multiWriter
+	case *boundLogger:
+		recorder = l.origin.multiWriter
+	}
+
+	r := classicRecordPool.Get().(*classicRecord)
+	r.event = event
+	extractContext(recorder, ctx, r)
+	r.event = nil
+	classicRecordPool.Put(r)
+}
+
+// appendGroups appends opened groups as nested objects, and the attributes of
+// record will be added in the innermost group. Empty groups will be omitted.
+func (h *slogHandler) appendGroups(event *LogEvent, record slog.Record) {
+	deepest := -1
+	if record.NumAttrs() > 0 {
+		deepest = len(h.groups) - 1
+	} else {
+		for i, members := range h.members {
+			if len(members) != 0 {
+				deepest = i
+			}
+		}
+	}
+	if deepest < 0 {
+		return
+	}
+
+	data := event.appender.Bytes()
+	for i := 0; i <= deepest; i++ {
+		if i > 0 {
+			data = appendSlogKey(data, h.groups[i])
+		}
+		data = append(data, '{')
+		data = appendSlogMembers(data, h.members[i])
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		data = appendSlogMember(data, attr)
+		return true
+	})
+	for i := 0; i <= deepest; i++ {
+		data = append(data, '}')
+	}
+
+	event.appendKeyValue(h.groups[0], data, false)
+}
+
+// isLevelEnabled checks if the given level is enabled in lork logger.
+func isLevelEnabled(logger ILogger, lvl Level) bool {
+	switch l := logger.(type) {
+	case *namedLogger:
+		return l.EffectiveLevel() <= lvl
+	case *boundLogger:
+		return l.origin.EffectiveLevel() <= lvl
+	case *substituteLogger:
+		if l.delegateLogger != nil {
+			return isLevelEnabled(l.delegateLogger, lvl)
+		}
+	}
+
+	return true
+}
+
+func slogLvlToLorkLvl(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return TraceLevel
+	case lvl < slog.LevelInfo:
+		return DebugLevel
+	case lvl < slog.LevelWarn:
+		return InfoLevel
+	case lvl < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// appendSlogAttr appends attribute as field of event.
+func appendSlogAttr(e *LogEvent, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := attr.Key
+	value := attr.Value
+	switch value.Kind() {
+	case slog.KindString:
+		e.appendString(key, value.String())
+	case slog.KindInt64:
+		e.appendInt(key, value.Int64())
+	case slog.KindUint64:
+		e.appendUint(key, value.Uint64())
+	case slog.KindFloat64:
+		e.appendFloat64(key, value.Float64())
+	case slog.KindBool:
+		e.appendBool(key, value.Bool())
+	case slog.KindDuration:
+		e.appendDuration(key, value.Duration())
+	case slog.KindTime:
+		e.appendTime(key, value.Time())
+	case slog.KindGroup:
+		members := value.Group()
+		if len(members) == 0 {
+			return
+		}
+		if len(key) == 0 {
+			// inline the members of group without key
+			for _, member := range members {
+				appendSlogAttr(e, member)
+			}
+			return
+		}
+		data := appendSlogValue(e.appender.Bytes(), value)
+		e.appendKeyValue(key, data, false)
+	default:
+		if err, ok := value.Any().(error); ok {
+			e.appendString(key, err.Error())
+			return
+		}
+		e.appendAny(key, value.Any())
+	}
+}
+
+// appendSlogMembers appends encoded members into json object.
+func appendSlogMembers(dst []byte, members []byte) []byte {
+	if len(members) == 0 {
+		return dst
+	}
+	if len(dst) != 0 && dst[len(dst)-1] != '{' {
+		dst = append(dst, ',')
+	}
+
+	return append(dst, members...)
+}
+
+// appendSlogMember appends attribute as member of json object.
+func appendSlogMember(dst []byte, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return dst
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		members := attr.Value.Group()
+		if len(members) == 0 {
+			return dst
+		}
+		if len(attr.Key) == 0 {
+			for _, member := range members {
+				dst = appendSlogMember(dst, member)
+			}
+			return dst
+		}
+	}
+
+	dst = appendSlogKey(dst, attr.Key)
+	return appendSlogValue(dst, attr.Value)
+}
+
+func appendSlogKey(dst []byte, key string) []byte {
+	if len(dst) != 0 && dst[len(dst)-1] != '{' {
+		dst = append(dst, ',')
+	}
+	dst = appendJsonString(dst, key)
+
+	return append(dst, ':')
+}
+
+// appendSlogValue appends resolved value as json value.
+func appendSlogValue(dst []byte, value slog.Value) []byte {
+	switch value.Kind() {
+	case slog.KindString:
+		return appendJsonString(dst, value.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(dst, value.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(dst, value.Uint64(), 10)
+	case slog.KindFloat64:
+		return appendJsonFloat(dst, value.Float64(), 64)
+	case slog.KindBool:
+		return strconv.AppendBool(dst, value.Bool())
+	case slog.KindDuration:
+		return strconv.AppendInt(dst, value.Duration().Nanoseconds(), 10)
+	case slog.KindTime:
+		dst = append(dst, '"')
+		dst, _ = appendFormat(dst, value.Time(), TimeFormatRFC3339)
+		return append(dst, '"')
+	case slog.KindGroup:
+		dst = append(dst, '{')
+		for _, member := range value.Group() {
+			dst = appendSlogMember(dst, member)
+		}
+		return append(dst, '}')
+	default:
+		if err, ok := value.Any().(error); ok {
+			return appendJsonString(dst, err.Error())
+		}
+		data, err := json.Marshal(value.Any())
+		if err != nil {
+			return appendJsonString(dst, "marshaling error: "+err.Error())
+		}
+		return append(dst, data...)
+	}
+}
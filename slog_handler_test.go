@@ -0,0 +1,92 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package lork
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("slog handler", func() {
+	var collector *eventCollector
+	var logger *slog.Logger
+	var encoder Encoder
+
+	ginkgo.BeforeEach(func() {
+		var ctx *LoggerContext
+		ctx, collector = newCollectorContext()
+		lorkLogger := ctx.Logger("github.com/coolerfall/lork")
+		lorkLogger.SetLevel(InfoLevel)
+		logger = slog.New(NewSlogHandler(func(o *SlogHandlerOption) {
+			o.Logger = lorkLogger
+			o.AddSource = true
+		}))
+		encoder = NewPatternEncoder(func(o *PatternEncoderOption) {
+			o.Pattern = "#level #logger #message #fields"
+		})
+	})
+
+	encode := func(e *LogEvent) string {
+		out, err := encoder.Encode(e)
+		Expect(err).To(BeNil())
+		return string(out)
+	}
+
+	ginkgo.It("level and attributes", func() {
+		logger.Debug("ignored")
+		Expect(collector.events).To(BeEmpty())
+
+		ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		r := slog.NewRecord(ts, slog.LevelWarn, "hello", 0)
+		r.AddAttrs(slog.String("str", "val"), slog.Int("int", 1), slog.Bool("bool", true),
+			slog.Any("err", errors.New("oops")), slog.Duration("dur", time.Second))
+		Expect(logger.Handler().Handle(context.Background(), r)).To(BeNil())
+		Expect(collector.last().Timestamp()).To(Equal(ts.UnixNano()))
+		Expect(encode(collector.last())).To(Equal("WARN github.com/coolerfall/lork hello " +
+			"str=val int=1 bool=true err=oops dur=1000000000\n"))
+	})
+	ginkgo.It("caller", func() {
+		line := currentLine()
+		logger.Error("hello")
+		Expect(string(collector.last().Caller())).To(Equal(line))
+	})
+	ginkgo.It("with attributes and groups", func() {
+		child := logger.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h")
+		child.Info("hello", "c", 3, slog.Group("d", "e", "f"))
+		Expect(encode(collector.last())).To(Equal("INFO github.com/coolerfall/lork hello " +
+			`a=1 g.b=2 g.h.c=3 g.h.d.e=f` + "\n"))
+
+		child.Info("empty")
+		Expect(encode(collector.last())).To(Equal("INFO github.com/coolerfall/lork empty " +
+			`a=1 g.b=2` + "\n"))
+
+		logger.WithGroup("x").Info("none", slog.Group("", "y", "z"))
+		Expect(encode(collector.last())).To(Equal("INFO github.com/coolerfall/lork none " +
+			`x.y=z` + "\n"))
+	})
+	ginkgo.It("json encode groups", func() {
+		logger.WithGroup("g").Info("hello", "k", "v\"")
+		out, err := NewJsonEncoder().Encode(collector.last())
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(HaveSuffix(`"message":"hello","g":{"k":"v\""}}` + "\n"))
+	})
+})
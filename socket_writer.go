@@ -15,8 +15,13 @@
 package lork
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -43,6 +48,11 @@ type socketWriter struct {
 	conn      *websocket.Conn
 	queue     *BlockingQueue
 	isStarted bool
+	pending   int64
+	failed    int64
+	done      chan struct{}
+	abort     chan struct{}
+	lastErr   atomic.Value
 
 	remoteUrl *url.URL
 }
@@ -66,10 +76,64 @@ func NewSocketWriter(options ...func(*SocketWriterOption)) Writer {
 	return NewBytesWriter(sw)
 }
 
+func init() {
+	RegisterWriter("socket", newSocketWriterFromConfig)
+}
+
+type socketWriterConfig struct {
+	RemoteUrl         string          `json:"remote_url"`
+	QueueSize         int             `json:"queue_size"`
+	ReconnectionDelay string          `json:"reconnection_delay"`
+	Filter            ComponentConfig `json:"filter"`
+}
+
+func newSocketWriterFromConfig(d OptionDecoder) (Writer, error) {
+	var c socketWriterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(c.RemoteUrl); err != nil || len(u.Host) == 0 ||
+		(u.Scheme != "ws" && u.Scheme != "wss") {
+		return nil, fmt.Errorf("invalid remote_url %q", c.RemoteUrl)
+	}
+	if c.QueueSize < 0 {
+		return nil, fmt.Errorf("invalid queue_size %d", c.QueueSize)
+	}
+	delay, err := parseDuration("reconnection_delay", c.ReconnectionDelay)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := d.Filter(c.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+
+	return NewSocketWriter(func(o *SocketWriterOption) {
+		o.Name = d.Name()
+		o.RemoteUrl = c.RemoteUrl
+		if c.QueueSize > 0 {
+			o.QueueSize = c.QueueSize
+		}
+		if delay > 0 {
+			o.ReconnectionDelay = delay
+		}
+		o.Filter = filter
+	}), nil
+}
+
 func (w *socketWriter) Start() {
+	if err := w.StartChecked(); err != nil {
+		ReportfExit("%v", err)
+	}
+}
+
+func (w *socketWriter) StartChecked() error {
 	w.locker.Lock()
 	defer w.locker.Unlock()
 
+	if w.isStarted {
+		return nil
+	}
 	if w.opts.QueueSize <= 0 {
 		w.opts.QueueSize = defaultSocketQueueSize
 	}
@@ -79,31 +143,55 @@ func (w *socketWriter) Start() {
 
 	remoteUrl, err := url.Parse(w.opts.RemoteUrl)
 	if err != nil {
-		ReportfExit("socket writer needs a available remote url: %v", err)
+		return fmt.Errorf("socket writer needs a available remote url: %v", err)
 	}
 
 	conn, _, err := websocket.DefaultDialer.Dial(remoteUrl.String(), nil)
 	if err != nil {
-		ReportfExit("connect socket server error, check your remote url: %v", err)
+		return fmt.Errorf("connect socket server error, check your remote url: %v", err)
 	}
 
 	w.remoteUrl = remoteUrl
 	w.conn = conn
 	w.queue = NewBlockingQueue(w.opts.QueueSize)
-
-	if w.isStarted {
-		return
-	}
 	w.isStarted = true
-	go w.startWorker()
+	w.done = make(chan struct{})
+	w.abort = make(chan struct{})
+	go w.startWorker(w.queue, w.done, w.abort)
+
+	return nil
 }
 
+// Stop stops the worker after all the queued messages are sent, then closes
+// the connection.
 func (w *socketWriter) Stop() {
+	w.StopContext(context.Background())
+}
+
+// StopContext stops the worker after all the queued messages are sent or ctx is
+// done, then closes the connection. The messages not sent will be dropped.
+func (w *socketWriter) StopContext(ctx context.Context) {
 	w.locker.Lock()
-	defer w.locker.Unlock()
+	if !w.isStarted {
+		w.locker.Unlock()
+		return
+	}
+	w.isStarted = false
+	queue, done, abort := w.queue, w.done, w.abort
+	w.locker.Unlock()
 
-	err := w.conn.Close()
-	if err != nil {
+	// nil is the signal for worker to exit after the queued messages
+	queue.Put(nil)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// closing the connection unblocks the message being sent
+		close(abort)
+		_ = w.connection().Close()
+		<-done
+	}
+
+	if err := w.connection().Close(); err != nil && !isClosedError(err) {
 		Reportf("stop socket writer error: %v", err)
 	}
 }
@@ -112,16 +200,40 @@ func (w *socketWriter) Write(p []byte) (int, error) {
 	w.locker.Lock()
 	defer w.locker.Unlock()
 
-	if w.queue.RemainCapacity() <= 2 {
+	if !w.isStarted || w.queue.RemainCapacity() <= 2 {
 		// discard
 		return 0, nil
 	}
 
-	w.queue.Put(p)
+	// the encoder will reuse its buffer, so copy before queueing
+	atomic.AddInt64(&w.pending, 1)
+	w.queue.Put(append([]byte(nil), p...))
 
 	return len(p), nil
 }
 
+// Health reports the last error if the message could not be sent to remote server.
+func (w *socketWriter) Health() error {
+	if s, ok := w.lastErr.Load().(socketState); ok && s.err != nil {
+		return s.err
+	}
+
+	return nil
+}
+
+// Flush blocks until all the queued messages are sent. The number of messages which
+// could not be sent since last flush will be reported.
+func (w *socketWriter) Flush(ctx context.Context) error {
+	if err := waitPending(ctx, &w.pending); err != nil {
+		return err
+	}
+	if n := atomic.SwapInt64(&w.failed, 0); n > 0 {
+		return fmt.Errorf("%d messages not sent: %v", n, w.Health())
+	}
+
+	return nil
+}
+
 func (w *socketWriter) Name() string {
 	return w.opts.Name
 }
@@ -134,29 +246,84 @@ func (w *socketWriter) Filter() Filter {
 	return w.opts.Filter
 }
 
-func (w *socketWriter) startWorker() {
+// socketState wraps the last error of socket writer to store in atomic.Value.
+type socketState struct {
+	err error
+}
+
+func (w *socketWriter) connection() *websocket.Conn {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	return w.conn
+}
+
+func (w *socketWriter) startWorker(queue *BlockingQueue, done, abort chan struct{}) {
+	defer close(done)
+
+	healthy := true
+	conn := w.connection()
 	for {
-		if !w.isStarted {
+		event, ok := (queue.Take()).([]byte)
+		if !ok {
 			break
 		}
 
-		event := (w.queue.Take()).([]byte)
-		err := w.conn.WriteMessage(websocket.BinaryMessage, event)
+		err := conn.WriteMessage(websocket.BinaryMessage, event)
+		atomic.AddInt64(&w.pending, -1)
 		if err == nil {
+			if !healthy {
+				healthy = true
+				w.lastErr.Store(socketState{})
+			}
 			continue
 		}
 
+		atomic.AddInt64(&w.failed, 1)
+		select {
+		case <-abort:
+			w.dropQueued(queue)
+			return
+		default:
+		}
+
 		// close first
-		_ = w.conn.Close()
+		_ = conn.Close()
 		Reportf("socket writer write error: %v", err)
+		healthy = false
+		w.lastErr.Store(socketState{err: err})
 
 		// delay before reconnect
-		time.Sleep(w.opts.ReconnectionDelay)
-		conn, _, err := websocket.DefaultDialer.Dial(w.remoteUrl.String(), nil)
+		select {
+		case <-time.After(w.opts.ReconnectionDelay):
+		case <-abort:
+			w.dropQueued(queue)
+			return
+		}
+		newConn, _, err := websocket.DefaultDialer.Dial(w.remoteUrl.String(), nil)
 		if err != nil {
 			Reportf("socket writer reconnect error: %v", err)
-		} else {
-			w.conn = conn
+			w.lastErr.Store(socketState{err: err})
+			continue
 		}
+		conn = newConn
+		w.locker.Lock()
+		w.conn = conn
+		w.locker.Unlock()
 	}
 }
+
+// dropQueued drops the messages left in queue when stopping is aborted.
+func (w *socketWriter) dropQueued(queue *BlockingQueue) {
+	for queue.Len() > 0 {
+		if _, ok := queue.Take().([]byte); ok {
+			atomic.AddInt64(&w.pending, -1)
+			atomic.AddInt64(&w.failed, 1)
+		}
+	}
+}
+
+// isClosedError checks if the error is caused by closing a closed connection.
+func isClosedError(err error) bool {
+	return errors.Is(err, net.ErrClosed)
+}
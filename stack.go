@@ -0,0 +1,85 @@
+// Copyright (c) 2019-2023 Vincent Cheung (coolingfall@gmail.com).
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lork
+
+import (
+	"runtime"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+)
+
+const (
+	maxStackDepth = 64
+)
+
+// StackTrace gets the stack trace of current goroutine as json array of frames.
+// The argument skip is the number of stack frames to ascend, with 0 identifying
+// the caller of StackTrace. Nil will be returned if skip is deeper than the stack.
+func StackTrace(skip int) []byte {
+	return appendStackTrace(nil, skip+1)
+}
+
+// appendStackTrace appends stack frames as json array to dst, nothing will be
+// appended if no frame found, so the stack can be omitted.
+func appendStackTrace(dst []byte, skip int) []byte {
+	var pcs [maxStackDepth]uintptr
+	// skip runtime.Callers and appendStackTrace
+	n := runtime.Callers(skip+2, pcs[:])
+	if n == 0 {
+		return dst
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+
+	dst = append(dst, '[')
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `{"func":`...)
+		dst = appendJsonString(dst, frame.Function)
+		dst = append(dst, `,"file":`...)
+		dst = appendJsonString(dst, frame.File)
+		dst = append(dst, `,"line":`...)
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, '}')
+		if !more {
+			break
+		}
+	}
+	dst = append(dst, ']')
+
+	return dst
+}
+
+// appendStackText appends the json stack frames as escaped text like the panic
+// output of go, e.g. func\n\tfile:line\n.
+func appendStackText(dst, stack []byte) []byte {
+	_, _ = jsonparser.ArrayEach(stack, func(frame []byte, _ jsonparser.ValueType,
+		_ int, _ error) {
+		fn, _, _, _ := jsonparser.Get(frame, "func")
+		file, _, _, _ := jsonparser.Get(frame, "file")
+		line, _, _, _ := jsonparser.Get(frame, "line")
+		dst = append(dst, fn...)
+		dst = append(dst, `\n\t`...)
+		dst = append(dst, file...)
+		dst = append(dst, ':')
+		dst = append(dst, line...)
+		dst = append(dst, `\n`...)
+	})
+
+	return dst
+}
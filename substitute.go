@@ -102,6 +102,16 @@ func (l *substituteLogger) Name() string {
 func (l *substituteLogger) SetLevel(Level) {
 }
 
+func (l *substituteLogger) SetCaller(bool, int) {
+}
+
+func (l *substituteLogger) SetStackLevel(Level) {
+}
+
+func (l *substituteLogger) With(fields func(Record)) ILogger {
+	return newSubstituteChildLogger(l, fields)
+}
+
 func (l *substituteLogger) Trace() Record {
 	return l.delegate().Trace()
 }
@@ -154,9 +164,99 @@ func (l *substituteLogger) delegate() ILogger {
 	return l.eventCacheLogger
 }
 
+// delegator represents a logger which delegates records to another logger.
+type delegator interface {
+	delegate() ILogger
+}
+
+// substituteChildLogger is a child of substitute logger with fields. The real child
+// logger will be created again once the delegate of parent changed.
+type substituteChildLogger struct {
+	parent         delegator
+	fields         func(Record)
+	locker         sync.Mutex
+	parentDelegate ILogger
+	delegateLogger ILogger
+}
+
+func newSubstituteChildLogger(parent delegator, fields func(Record)) ILogger {
+	return &substituteChildLogger{
+		parent: parent,
+		fields: fields,
+	}
+}
+
+func (l *substituteChildLogger) Name() string {
+	return l.delegate().Name()
+}
+
+func (l *substituteChildLogger) SetLevel(Level) {
+}
+
+func (l *substituteChildLogger) SetCaller(bool, int) {
+}
+
+func (l *substituteChildLogger) SetStackLevel(Level) {
+}
+
+func (l *substituteChildLogger) With(fields func(Record)) ILogger {
+	return newSubstituteChildLogger(l, fields)
+}
+
+func (l *substituteChildLogger) Trace() Record {
+	return l.delegate().Trace()
+}
+
+func (l *substituteChildLogger) Debug() Record {
+	return l.delegate().Debug()
+}
+
+func (l *substituteChildLogger) Info() Record {
+	return l.delegate().Info()
+}
+
+func (l *substituteChildLogger) Warn() Record {
+	return l.delegate().Warn()
+}
+
+func (l *substituteChildLogger) Error() Record {
+	return l.delegate().Error()
+}
+
+func (l *substituteChildLogger) Fatal() Record {
+	return l.delegate().Fatal()
+}
+
+func (l *substituteChildLogger) Panic() Record {
+	return l.delegate().Panic()
+}
+
+func (l *substituteChildLogger) Level(lvl Level) Record {
+	return l.delegate().Level(lvl)
+}
+
+func (l *substituteChildLogger) Event(e *LogEvent) {
+	l.delegate().Event(e)
+}
+
+func (l *substituteChildLogger) delegate() ILogger {
+	parentDelegate := l.parent.delegate()
+
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	if l.parentDelegate != parentDelegate {
+		l.parentDelegate = parentDelegate
+		l.delegateLogger = parentDelegate.With(l.fields)
+	}
+
+	return l.delegateLogger
+}
+
 type eventCacheLogger struct {
 	name     string
 	recorder EventRecorder
+	fields   *LogEvent
 }
 
 func newEventCacheLogger(name string, r EventRecorder) ILogger {
@@ -173,6 +273,20 @@ func (l *eventCacheLogger) Name() string {
 func (l *eventCacheLogger) SetLevel(Level) {
 }
 
+func (l *eventCacheLogger) SetCaller(bool, int) {
+}
+
+func (l *eventCacheLogger) SetStackLevel(Level) {
+}
+
+func (l *eventCacheLogger) With(fields func(Record)) ILogger {
+	return &eventCacheLogger{
+		name:     l.name,
+		recorder: l.recorder,
+		fields:   captureFields(l.fields, fields),
+	}
+}
+
 func (l *eventCacheLogger) Trace() Record {
 	return l.newRecord(TraceLevel)
 }
@@ -212,7 +326,7 @@ func (l *eventCacheLogger) Event(e *LogEvent) {
 }
 
 func (l *eventCacheLogger) newRecord(lvl Level) Record {
-	return newClassicRecord(lvl, l.recorder).Str(LoggerNameFieldKey, l.name)
+	return newClassicRecord(lvl, l.recorder, l.fields).Str(LoggerNameFieldKey, l.name)
 }
 
 type substituteWriter struct {
@@ -18,6 +18,7 @@
 package lork
 
 import (
+	"fmt"
 	"log/syslog"
 	"sync"
 )
@@ -57,21 +58,59 @@ func NewSyslogWriter(options ...func(option *SyslogWriterOption)) Writer {
 	return NewEventWriter(sw)
 }
 
+func init() {
+	RegisterWriter("syslog", newSyslogWriterFromConfig)
+}
+
+type syslogWriterConfig struct {
+	Tag     string          `json:"tag"`
+	Address string          `json:"address"`
+	Network string          `json:"network"`
+	Filter  ComponentConfig `json:"filter"`
+}
+
+func newSyslogWriterFromConfig(d OptionDecoder) (Writer, error) {
+	var c syslogWriterConfig
+	if err := d.Decode(&c); err != nil {
+		return nil, err
+	}
+	filter, err := d.Filter(c.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+
+	return NewSyslogWriter(func(o *SyslogWriterOption) {
+		o.Name = d.Name()
+		o.Tag = c.Tag
+		o.Address = c.Address
+		o.Network = c.Network
+		o.Filter = filter
+	}), nil
+}
+
 func (w *syslogWriter) Start() {
+	if err := w.StartChecked(); err != nil {
+		ReportfExit("%v", err)
+	}
+}
+
+func (w *syslogWriter) StartChecked() error {
 	w.locker.Lock()
 	defer w.locker.Unlock()
 
 	if w.isStarted {
-		return
+		return nil
 	}
 
 	sw, err := syslog.Dial(w.opts.Address, w.opts.Network, syslog.LOG_DEBUG, w.opts.Tag)
 	if err != nil {
-		ReportfExit("failed to dial syslog: %v", err)
+		return fmt.Errorf("failed to dial syslog: %v", err)
 	}
 
 	w.sw = sw
 	w.isStarted = true
+
+	return nil
 }
 
 func (w *syslogWriter) Stop() {
@@ -15,9 +15,14 @@
 package lork
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Writer is interface represents the raw writer of lork.
@@ -80,33 +85,100 @@ type WriterAttachable interface {
 	ResetWriter()
 }
 
+// HealthChecker represents a writer which can report its health.
+type HealthChecker interface {
+	// Health returns nil if the writer is healthy, or the reason why it's not.
+	Health() error
+}
+
+// FlushError represents the writers which could not be flushed, and the reason of each.
+type FlushError struct {
+	Writers []string
+	Errs    []error
+}
+
+func (e *FlushError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("writers not flushed: ")
+	for i, name := range e.Writers {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(fmt.Sprintf("[%s] %v", name, e.Errs[i]))
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the error of the first writer not flushed.
+func (e *FlushError) Unwrap() error {
+	if len(e.Errs) == 0 {
+		return nil
+	}
+
+	return e.Errs[0]
+}
+
 // MultiWriter represents multiple writer which implements EventWriter.
 // This writer is used as output which will implement ILogger.
 type MultiWriter struct {
-	locker  sync.Mutex
+	// inflight counts the events being written in each epoch, a grace period flips the
+	// epoch and waits until the events of previous epoch are written.
+	inflight    [2]int64
+	epoch       int64
+	graceLocker sync.Mutex
+
+	locker     sync.Mutex
+	set        atomic.Value
+	hooks      *hookChain
+	routes     *writerRoutes
+	extractors *contextExtractors
+}
+
+// writerSet holds the writers of multi writer and named loggers, it's immutable once
+// published.
+type writerSet struct {
 	writers []Writer
+	named   map[string]*loggerWriters
 }
 
 // NewMultiWriter creates a new multiple writer.
 func NewMultiWriter() *MultiWriter {
-	return &MultiWriter{
-		writers: make([]Writer, 0),
-	}
+	mw := &MultiWriter{}
+	mw.set.Store(&writerSet{})
+
+	return mw
 }
 
 // AddWriter adds a lork writer into multi writer.
 func (mw *MultiWriter) AddWriter(writers ...Writer) {
-	for _, w := range writers {
-		if lc, ok := w.(Lifecycle); ok {
-			lc.Start()
-		}
+	mw.locker.Lock()
+	defer mw.locker.Unlock()
+
+	startWriters(writers)
+	mw.update(func(set *writerSet) {
+		set.writers = append(set.writers, writers...)
+	})
+}
+
+// addWriterChecked starts and adds the writers into multi writer, none of them will
+// be added if any writer fails to start.
+func (mw *MultiWriter) addWriterChecked(writers ...Writer) error {
+	mw.locker.Lock()
+	defer mw.locker.Unlock()
 
-		mw.writers = append(mw.writers, w)
+	if err := startWritersChecked(writers); err != nil {
+		return err
 	}
+	mw.update(func(set *writerSet) {
+		set.writers = append(set.writers, writers...)
+	})
+
+	return nil
 }
 
 func (mw *MultiWriter) GetWriter(name string) Writer {
-	for _, w := range mw.writers {
+	for _, w := range mw.load().writers {
 		if w.Name() == name {
 			return w
 		}
@@ -116,7 +188,7 @@ func (mw *MultiWriter) GetWriter(name string) Writer {
 }
 
 func (mw *MultiWriter) Attached(writer Writer) bool {
-	for _, w := range mw.writers {
+	for _, w := range mw.load().writers {
 		if reflect.DeepEqual(w, writer) {
 			return true
 		}
@@ -126,23 +198,65 @@ func (mw *MultiWriter) Attached(writer Writer) bool {
 }
 
 func (mw *MultiWriter) ResetWriter() {
+	mw.resetWriter(context.Background())
+}
+
+// resetWriter removes and stops all the writers, until ctx is done.
+func (mw *MultiWriter) resetWriter(ctx context.Context) {
 	mw.locker.Lock()
-	defer mw.locker.Unlock()
+	old := mw.update(func(set *writerSet) {
+		set.writers = nil
+	})
+	mw.locker.Unlock()
 
-	for _, w := range mw.writers {
-		if lc, ok := w.(Lifecycle); ok {
-			lc.Stop()
+	mw.grace(ctx)
+	stopWriters(ctx, old.writers)
+}
+
+// Flush flushes all the writers which implement Flushable, including the writers
+// attached to named loggers. A FlushError will be returned if any writer could not
+// be flushed before the context is done.
+func (mw *MultiWriter) Flush(ctx context.Context) error {
+	set := mw.load()
+	writers := append([]Writer(nil), set.writers...)
+	for _, lw := range set.named {
+		writers = append(writers, lw.writers...)
+	}
+
+	var fe *FlushError
+	for _, w := range writers {
+		if err := flush(ctx, w); err != nil {
+			if fe == nil {
+				fe = &FlushError{}
+			}
+			fe.Writers = append(fe.Writers, w.Name())
+			fe.Errs = append(fe.Errs, err)
 		}
 	}
-	mw.writers = mw.writers[:0]
+	if fe != nil {
+		return fe
+	}
+
+	return nil
+}
+
+// Health checks the health of all the writers, and reports the first unhealthy one.
+func (mw *MultiWriter) Health() error {
+	for _, w := range mw.load().writers {
+		if err := checkHealth(w); err != nil {
+			return fmt.Errorf("writer [%s]: %w", w.Name(), err)
+		}
+	}
+
+	return nil
 }
 
 func (mw *MultiWriter) Size() int {
-	return len(mw.writers)
+	return len(mw.load().writers)
 }
 
 func (mw *MultiWriter) Write(p []byte) (n int, err error) {
-	if len(mw.writers) == 0 {
+	if mw.Size() == 0 && (mw.routes == nil || mw.routes.empty()) {
 		return 0, nil
 	}
 
@@ -154,15 +268,142 @@ func (mw *MultiWriter) Write(p []byte) (n int, err error) {
 func (mw *MultiWriter) WriteEvent(event *LogEvent) (err error) {
 	defer event.Recycle()
 
-	for _, w := range mw.writers {
-		if err = w.DoWrite(event); err != nil {
+	// resolve lazy fields once, the event is shared by hooks and writers
+	event.resolveLazies()
+	// fire hooks before fanning out, the event is dropped if any hook refuses it
+	if mw.hooks != nil && !mw.hooks.fire(event) {
+		return nil
+	}
+	mw.writeToAll(event)
+
+	return nil
+}
+
+// writeToAll writes event into all writers without firing hooks. The writers of
+// named loggers will be written first, and the writers of this multi writer will be
+// skipped if any of named loggers is not additive. No lock is held while writing.
+func (mw *MultiWriter) writeToAll(event *LogEvent) {
+	slot := mw.enter()
+	defer atomic.AddInt64(&mw.inflight[slot], -1)
+
+	set := mw.load()
+	if mw.routes != nil && !writeNamed(set.named, event) {
+		return
+	}
+	writeTo(set.writers, event)
+}
+
+// swap replaces the writers of this multi writer and named loggers with the ones
+// returned by f. Each event is written into either the old writers or the new ones,
+// never both. The old writers are not stopped, but they are not being written any
+// more when swap returns.
+func (mw *MultiWriter) swap(f func(writers []Writer,
+	named map[string]*loggerWriters) ([]Writer, map[string]*loggerWriters)) {
+	mw.locker.Lock()
+	old := mw.load()
+	writers, named := f(old.writers, old.named)
+	mw.set.Store(&writerSet{
+		writers: writers,
+		named:   named,
+	})
+	mw.locker.Unlock()
+
+	mw.grace(context.Background())
+}
+
+func (mw *MultiWriter) load() *writerSet {
+	return mw.set.Load().(*writerSet)
+}
+
+// enter counts an event being written in current epoch, and returns the slot of
+// counter which must be decreased after writing.
+func (mw *MultiWriter) enter() int {
+	for {
+		epoch := atomic.LoadInt64(&mw.epoch)
+		slot := int(epoch & 1)
+		atomic.AddInt64(&mw.inflight[slot], 1)
+		if atomic.LoadInt64(&mw.epoch) == epoch {
+			return slot
+		}
+		// a grace period started before counted, retry with the new epoch
+		atomic.AddInt64(&mw.inflight[slot], -1)
+	}
+}
+
+// grace waits until the events which started writing before are written, or ctx
+// is done. The writer sets replaced before are not written any more after that.
+func (mw *MultiWriter) grace(ctx context.Context) {
+	mw.graceLocker.Lock()
+	defer mw.graceLocker.Unlock()
+
+	previous := atomic.AddInt64(&mw.epoch, 1) - 1
+	_ = waitPending(ctx, &mw.inflight[previous&1])
+}
+
+// update copies the current writer set and publishes it after updated by f, the old
+// set will be returned. This must be called with locker held.
+func (mw *MultiWriter) update(f func(set *writerSet)) *writerSet {
+	old := mw.load()
+	set := &writerSet{
+		writers: append([]Writer(nil), old.writers...),
+		named:   make(map[string]*loggerWriters, len(old.named)),
+	}
+	for k, v := range old.named {
+		set.named[k] = v
+	}
+	f(set)
+	mw.set.Store(set)
+
+	return old
+}
+
+// writeTo writes event into given writers.
+func writeTo(writers []Writer, event *LogEvent) {
+	for _, w := range writers {
+		if err := w.DoWrite(event); err != nil {
 			Reportf("write event with writer [%v] error: %v", w.Name(), err)
 		}
 	}
+}
+
+// checkHealth checks the health of given writer if it implements HealthChecker.
+func checkHealth(v interface{}) error {
+	if hc, ok := v.(HealthChecker); ok {
+		return hc.Health()
+	}
 
 	return nil
 }
 
+// startWriters starts the writers which implement Lifecycle.
+func startWriters(writers []Writer) {
+	for _, w := range writers {
+		if lc, ok := w.(Lifecycle); ok {
+			lc.Start()
+		}
+	}
+}
+
+// startWritersChecked starts the writers which implement Lifecycle, and stops the
+// started ones if any of them fails to start.
+func startWritersChecked(writers []Writer) error {
+	for i, w := range writers {
+		if err := startChecked(w); err != nil {
+			stopWriters(context.Background(), writers[:i])
+			return fmt.Errorf("start writer [%v] error: %w", w.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// stopWriters stops the writers which implement Lifecycle, until ctx is done.
+func stopWriters(ctx context.Context, writers []Writer) {
+	for _, w := range writers {
+		stopContext(ctx, w)
+	}
+}
+
 type eventWriter struct {
 	ref EventWriter
 }
@@ -175,15 +416,36 @@ func NewEventWriter(w EventWriter) Writer {
 }
 
 func (w *eventWriter) Start() {
-	if lw, ok := w.ref.(Lifecycle); ok {
-		lw.Start()
+	if err := w.StartChecked(); err != nil {
+		ReportfExit("start writer [%v] error: %v", w.ref.Name(), err)
 	}
 }
 
-func (w *eventWriter) Stop() {
-	if lw, ok := w.ref.(Lifecycle); ok {
-		lw.Stop()
+func (w *eventWriter) StartChecked() error {
+	if err := startChecked(w.ref); err != nil {
+		return err
 	}
+
+	startFilter(w.ref.Filter(), w.ref.Write)
+
+	return nil
+}
+
+func (w *eventWriter) Stop() {
+	w.StopContext(context.Background())
+}
+
+func (w *eventWriter) StopContext(ctx context.Context) {
+	stopFilter(w.ref.Filter())
+	stopContext(ctx, w.ref)
+}
+
+func (w *eventWriter) Flush(ctx context.Context) error {
+	return flush(ctx, w.ref)
+}
+
+func (w *eventWriter) Health() error {
+	return checkHealth(w.ref)
 }
 
 func (w *eventWriter) Name() string {
@@ -199,31 +461,57 @@ func (w *eventWriter) DoWrite(event *LogEvent) error {
 }
 
 type bytesWriter struct {
-	ref BytesWriter
+	ref    BytesWriter
+	locker sync.Locker
 }
 
 // NewBytesWriter creates a Writer with given BytesWriter. BytesWriter will
 // write data synchronously cause the order of goroutine is messy.
 func NewBytesWriter(w BytesWriter) Writer {
-	return NewSyncWriter(&bytesWriter{
+	bw := &bytesWriter{
 		ref: w,
-	})
+	}
+	sw := NewSyncWriter(bw).(*syncWriter)
+	bw.locker = sw.locker
+
+	return sw
 }
 
 func (w *bytesWriter) Start() {
+	if err := w.StartChecked(); err != nil {
+		ReportfExit("start writer [%v] error: %v", w.ref.Name(), err)
+	}
+}
+
+func (w *bytesWriter) StartChecked() error {
 	if w.ref.Encoder() == nil {
-		ReportfExit("no encoder found in writer: %v", w.ref.Name())
+		return errors.New("no encoder found")
 	}
 
-	if lw, ok := w.ref.(Lifecycle); ok {
-		lw.Start()
+	if err := startChecked(w.ref); err != nil {
+		return err
 	}
+
+	startFilter(w.ref.Filter(), w.emit)
+
+	return nil
 }
 
 func (w *bytesWriter) Stop() {
-	if lw, ok := w.ref.(Lifecycle); ok {
-		lw.Stop()
-	}
+	w.StopContext(context.Background())
+}
+
+func (w *bytesWriter) StopContext(ctx context.Context) {
+	stopFilter(w.ref.Filter())
+	stopContext(ctx, w.ref)
+}
+
+func (w *bytesWriter) Flush(ctx context.Context) error {
+	return flush(ctx, w.ref)
+}
+
+func (w *bytesWriter) Health() error {
+	return checkHealth(w.ref)
 }
 
 func (w *bytesWriter) Name() string {
@@ -235,6 +523,19 @@ func (w *bytesWriter) DoWrite(event *LogEvent) error {
 		return nil
 	}
 
+	return w.write(event)
+}
+
+// emit writes event emitted by filter with the same lock of writing.
+func (w *bytesWriter) emit(event *LogEvent) error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	return w.write(event)
+}
+
+// write encodes and writes event without filter.
+func (w *bytesWriter) write(event *LogEvent) error {
 	encoded, err := w.ref.Encoder().Encode(event)
 	if err != nil {
 		return err
@@ -267,20 +568,54 @@ func (w *syncWriter) DoWrite(event *LogEvent) error {
 	w.locker.Lock()
 	defer w.locker.Unlock()
 
-	// append current timestamp before writing in goroutine
-	event.appendTimestamp()
+	// append current timestamp before writing in goroutine if not set
+	if event.unixNano == 0 {
+		event.appendTimestamp()
+	}
 
 	return w.ref.DoWrite(event)
 }
 
+func (w *syncWriter) Flush(ctx context.Context) error {
+	return flush(ctx, w.ref)
+}
+
+func (w *syncWriter) Health() error {
+	return checkHealth(w.ref)
+}
+
 func (w *syncWriter) Start() {
 	if lw, ok := w.ref.(Lifecycle); ok {
 		lw.Start()
 	}
 }
 
+func (w *syncWriter) StartChecked() error {
+	return startChecked(w.ref)
+}
+
 func (w *syncWriter) Stop() {
-	if lw, ok := w.ref.(Lifecycle); ok {
-		lw.Stop()
+	w.StopContext(context.Background())
+}
+
+func (w *syncWriter) StopContext(ctx context.Context) {
+	stopContext(ctx, w.ref)
+}
+
+// startFilter attaches the emit function to filter if it's EmittingFilter, and
+// starts filter if it implements Lifecycle.
+func startFilter(filter Filter, emit func(event *LogEvent) error) {
+	if ef, ok := filter.(EmittingFilter); ok {
+		ef.Attach(emit)
+	}
+	if lc, ok := filter.(Lifecycle); ok {
+		lc.Start()
+	}
+}
+
+// stopFilter stops filter if it implements Lifecycle.
+func stopFilter(filter Filter) {
+	if lc, ok := filter.(Lifecycle); ok {
+		lc.Stop()
 	}
 }